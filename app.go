@@ -29,10 +29,17 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
+	"log/slog"
 	"maps"
+	"net/url"
 	"os"
+	"path/filepath"
+	"slices"
 	"sort"
 	"strconv"
+	"strings"
+	"time"
 
 	"buf.build/go/interrupt"
 )
@@ -76,6 +83,65 @@ func NewEnvContainerWithOverrides(envContainer EnvContainer, overrides map[strin
 	return newEnvContainer(m)
 }
 
+// NewEnvContainerForDotenv returns a new EnvContainer with the variables parsed from the
+// dotenv-formatted data layered under the variables of the input EnvContainer.
+//
+// Variables already set in the input EnvContainer take precedence over the variables in the
+// data, so that the environment can always override a dotenv file.
+//
+// The data consists of KEY=VALUE lines, optionally prefixed with "export ". Lines starting
+// with # are comments. Values may be:
+//
+//   - Unquoted, in which case surrounding whitespace and comments starting with " #" are removed.
+//   - Single-quoted, in which case the value is taken literally and may span multiple lines.
+//   - Double-quoted, in which case the escapes \n, \r, \t, \\, \", and \$ are supported,
+//     and the value may span multiple lines.
+//
+// Unquoted and double-quoted values expand $VAR, ${VAR}, and ${VAR:-default} references
+// against the input EnvContainer and the variables defined earlier in the data.
+//
+// Errors contain line numbers and variable names, but never values.
+func NewEnvContainerForDotenv(envContainer EnvContainer, data []byte) (EnvContainer, error) {
+	values, err := parseDotenv(envContainer, data)
+	if err != nil {
+		return nil, err
+	}
+	m := EnvironMap(envContainer)
+	for key, value := range values {
+		if _, ok := m[key]; !ok {
+			m[key] = value
+		}
+	}
+	return newEnvContainer(m), nil
+}
+
+// RedactedEnvContainer is an EnvContainer that redacts the values of sensitive environment variables.
+//
+// It also implements slog.LogValuer, so that it can be safely logged with slog.Any.
+type RedactedEnvContainer interface {
+	EnvContainer
+	slog.LogValuer
+}
+
+// NewRedactedEnvContainer returns a new RedactedEnvContainer for the input EnvContainer.
+//
+// The values of environment variables whose keys match any of the keyPatterns are replaced
+// with "[REDACTED]". The keyPatterns use the syntax of path.Match, and are matched
+// case-insensitively. If no keyPatterns are given, DefaultRedactedEnvKeyPatterns are used.
+//
+// Only the values of matching keys are redacted. Secrets in the values of other keys, such as
+// credentials in a DATABASE_URL, are not redacted.
+func NewRedactedEnvContainer(envContainer EnvContainer, keyPatterns ...string) RedactedEnvContainer {
+	return newRedactedEnvContainer(envContainer, keyPatterns)
+}
+
+// DefaultRedactedEnvKeyPatterns returns the default key patterns for NewRedactedEnvContainer.
+//
+// These match keys containing TOKEN, SECRET, or PASSWORD, and keys ending in _KEY.
+func DefaultRedactedEnvKeyPatterns() []string {
+	return slices.Clone(defaultRedactedEnvKeyPatterns)
+}
+
 // StdinContainer provides stdin.
 type StdinContainer interface {
 	// Stdin provides stdin.
@@ -150,16 +216,335 @@ func NewArgContainerForOS() ArgContainer {
 	return newArgContainer(os.Args)
 }
 
-// Container contains environment variables, args, and stdio.
+// File is an open file.
+//
+// *os.File implements File.
+type File interface {
+	io.Reader
+	io.Writer
+	io.Closer
+
+	// Name returns the name of the file as presented to OpenFile.
+	Name() string
+	// Stat returns the FileInfo for the file.
+	Stat() (fs.FileInfo, error)
+}
+
+// FileSystem provides file system operations.
+//
+// The methods match their equivalents in the os package, including the returned
+// errors, which can be checked with errors.Is against the fs.Err* values.
+type FileSystem interface {
+	// OpenFile matches os.OpenFile.
+	//
+	// The flags os.O_RDONLY, os.O_WRONLY, os.O_RDWR, os.O_APPEND, os.O_CREATE, os.O_EXCL,
+	// and os.O_TRUNC are supported.
+	OpenFile(name string, flag int, perm fs.FileMode) (File, error)
+	// ReadFile matches os.ReadFile.
+	ReadFile(name string) ([]byte, error)
+	// WriteFile matches os.WriteFile.
+	WriteFile(name string, data []byte, perm fs.FileMode) error
+	// Stat matches os.Stat.
+	Stat(name string) (fs.FileInfo, error)
+	// ReadDir matches os.ReadDir.
+	ReadDir(name string) ([]fs.DirEntry, error)
+	// MkdirAll matches os.MkdirAll.
+	MkdirAll(path string, perm fs.FileMode) error
+	// Remove matches os.Remove.
+	Remove(name string) error
+	// RemoveAll matches os.RemoveAll.
+	RemoveAll(path string) error
+	// Rename matches os.Rename.
+	Rename(oldpath string, newpath string) error
+	// WalkDir matches filepath.WalkDir.
+	WalkDir(root string, f fs.WalkDirFunc) error
+	// Chtimes matches os.Chtimes.
+	Chtimes(name string, atime time.Time, mtime time.Time) error
+	// EvalSymlinks matches filepath.EvalSymlinks.
+	EvalSymlinks(path string) (string, error)
+	// TryLock acquires an advisory lock on the file without waiting, creating the file if
+	// it does not exist.
+	//
+	// If shared is true, a shared lock is acquired, which any number of holders can hold
+	// at the same time. Otherwise, an exclusive lock is acquired. Returns an error that wraps
+	// ErrLocked if the lock is held by another holder, including within the same process.
+	//
+	// The lock is released by calling the returned function. Locks of the operating system
+	// are released by the operating system when the process exits. The holder may remove
+	// the file before releasing the lock, in which case the next holder locks a new file.
+	TryLock(name string, shared bool) (func() error, error)
+}
+
+// ErrLocked is the error wrapped by the errors of FileSystem.TryLock if the lock is held.
+var ErrLocked = errors.New("file is locked")
+
+// NewFileSystemForOS returns a new FileSystem for the operating system.
+//
+// Locks are held with flock on unix-like platforms and with LockFileEx on windows. Other
+// platforms, including aix, only have locks within the process.
+func NewFileSystemForOS() FileSystem {
+	return newOSFileSystem()
+}
+
+// NewInMemoryFileSystem returns a new empty in-memory FileSystem.
+//
+// The roots "/" and "." always exist as directories.
+// All other directories must be created before files are written to them, as with the os package.
+func NewInMemoryFileSystem() FileSystem {
+	return newMemoryFileSystem()
+}
+
+// FileSystemContainer provides the file system.
+type FileSystemContainer interface {
+	// FileSystem provides the file system.
+	FileSystem() FileSystem
+}
+
+// NewFileSystemContainer returns a new FileSystemContainer.
+//
+// If fileSystem is nil, a new in-memory FileSystem is used.
+func NewFileSystemContainer(fileSystem FileSystem) FileSystemContainer {
+	return newFileSystemContainer(fileSystem)
+}
+
+// NewFileSystemContainerForOS returns a new FileSystemContainer for the operating system.
+func NewFileSystemContainerForOS() FileSystemContainer {
+	return newFileSystemContainer(NewFileSystemForOS())
+}
+
+// WorkDirContainer provides the working directory.
+type WorkDirContainer interface {
+	// WorkDirPath provides the working directory path.
+	//
+	// Relative paths should be resolved against this path instead of the working
+	// directory of the process, see ResolvePath.
+	WorkDirPath() string
+}
+
+// NewWorkDirContainer returns a new WorkDirContainer.
+//
+// If workDirPath is empty, "." is used.
+func NewWorkDirContainer(workDirPath string) WorkDirContainer {
+	return newWorkDirContainer(workDirPath)
+}
+
+// NewWorkDirContainerForOS returns a new WorkDirContainer for the operating system.
+func NewWorkDirContainerForOS() (WorkDirContainer, error) {
+	workDirPath, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	return newWorkDirContainer(workDirPath), nil
+}
+
+// Clock provides the current time and timers.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// After waits for the duration to elapse and then sends the current time on the returned channel.
+	After(d time.Duration) <-chan time.Time
+	// NewTimer creates a new Timer that will send the current time on its channel
+	// after at least the duration d.
+	NewTimer(d time.Duration) Timer
+}
+
+// Timer is a timer created by a Clock.
+type Timer interface {
+	// C returns the channel on which the time is delivered.
+	C() <-chan time.Time
+	// Stop prevents the Timer from firing.
+	//
+	// Returns true if the call stops the timer, false if the timer has already
+	// expired or been stopped.
+	Stop() bool
+	// Reset changes the timer to expire after the duration d.
+	//
+	// Returns true if the timer had been active, false if the timer had
+	// expired or been stopped.
+	Reset(d time.Duration) bool
+}
+
+// NewClockForOS returns a new Clock for the operating system.
+func NewClockForOS() Clock {
+	return newOSClock()
+}
+
+// FakeClock is a Clock that only advances when Advance is called.
+//
+// This is meant for testing.
+type FakeClock interface {
+	Clock
+
+	// Advance advances the clock by the duration d, firing any timers that expire.
+	Advance(d time.Duration)
+}
+
+// NewFakeClock returns a new FakeClock set to now.
+func NewFakeClock(now time.Time) FakeClock {
+	return newFakeClock(now)
+}
+
+// ClockContainer provides the Clock.
+type ClockContainer interface {
+	// Clock provides the Clock.
+	Clock() Clock
+}
+
+// NewClockContainer returns a new ClockContainer.
+//
+// If clock is nil, the Clock for the operating system is used.
+func NewClockContainer(clock Clock) ClockContainer {
+	return newClockContainer(clock)
+}
+
+// NewClockContainerForOS returns a new ClockContainer for the operating system.
+func NewClockContainerForOS() ClockContainer {
+	return newClockContainer(NewClockForOS())
+}
+
+// ExecCommand is an external command to be run by a Runner.
+type ExecCommand struct {
+	// Name is the name or path of the program to run.
+	//
+	// If Name contains no path separators, it is resolved using $PATH from Env, not the
+	// $PATH of the process, so that the program is found in the same environment that it
+	// runs in. Relative entries in $PATH are ignored, as with os/exec.
+	Name string
+	// Args are the arguments to the program, not including the program name.
+	Args []string
+	// Env is the environment of the program, in the form "KEY=VALUE".
+	//
+	// If Env is empty, the program is run with an empty environment.
+	Env []string
+	// Dir is the working directory of the program.
+	Dir string
+	// Stdin is the stdin of the program.
+	Stdin io.Reader
+	// Stdout is the stdout of the program.
+	Stdout io.Writer
+	// Stderr is the stderr of the program.
+	Stderr io.Writer
+}
+
+// Runner runs external commands.
+type Runner interface {
+	// Run runs the command and waits for it to complete.
+	//
+	// If the command exits with a non-zero exit code, the returned error should
+	// have a method ExitCode() int that returns the exit code, as *exec.ExitError does,
+	// or should have been created by this package with an exit code.
+	Run(ctx context.Context, command *ExecCommand) error
+}
+
+// NewRunnerForOS returns a new Runner for the operating system.
+//
+// If the context is canceled, which includes when an interrupt signal is received,
+// the running program is sent an interrupt signal. If the program does not exit
+// in a reasonable amount of time after this, it is killed.
+func NewRunnerForOS() Runner {
+	return newOSRunner()
+}
+
+// FakeRunner is a Runner that records all commands it was asked to run.
+//
+// This is meant for testing.
+type FakeRunner interface {
+	Runner
+
+	// Commands returns the commands that have been run, in order.
+	Commands() []*ExecCommand
+}
+
+// NewFakeRunner returns a new FakeRunner.
+//
+// All commands are delegated to run. If run is nil, all commands succeed without
+// doing anything.
+func NewFakeRunner(run func(context.Context, *ExecCommand) error) FakeRunner {
+	return newFakeRunner(run)
+}
+
+// RunnerContainer provides the Runner.
+type RunnerContainer interface {
+	// Runner provides the Runner.
+	Runner() Runner
+}
+
+// NewRunnerContainer returns a new RunnerContainer.
+//
+// If runner is nil, a Runner that returns an error for every command is used.
+func NewRunnerContainer(runner Runner) RunnerContainer {
+	return newRunnerContainer(runner)
+}
+
+// NewRunnerContainerForOS returns a new RunnerContainer for the operating system.
+func NewRunnerContainerForOS() RunnerContainer {
+	return newRunnerContainer(NewRunnerForOS())
+}
+
+// TerminalContainer provides information on the terminals attached to stdio.
+type TerminalContainer interface {
+	// IsStdinTerminal returns true if stdin is a terminal.
+	IsStdinTerminal() bool
+	// IsStdoutTerminal returns true if stdout is a terminal.
+	IsStdoutTerminal() bool
+	// IsStderrTerminal returns true if stderr is a terminal.
+	IsStderrTerminal() bool
+	// TerminalSize returns the width and height of the terminal in characters.
+	//
+	// The size is determined from stdout, falling back to stderr and then stdin.
+	// Returns 0, 0 if the size is not known.
+	TerminalSize() (width int, height int)
+}
+
+// NewTerminalContainer returns a new TerminalContainer with the given values.
+//
+// Negative sizes are treated as 0.
+func NewTerminalContainer(
+	isStdinTerminal bool,
+	isStdoutTerminal bool,
+	isStderrTerminal bool,
+	width int,
+	height int,
+) TerminalContainer {
+	return newTerminalContainer(
+		isStdinTerminal,
+		isStdoutTerminal,
+		isStderrTerminal,
+		width,
+		height,
+	)
+}
+
+// NewTerminalContainerForOS returns a new TerminalContainer for the operating system.
+//
+// Terminals are detected using ioctl on linux. On other platforms, stdio is never
+// considered to be a terminal.
+func NewTerminalContainerForOS() TerminalContainer {
+	return newOSTerminalContainer()
+}
+
+// Container contains environment variables, args, stdio, the file system, the working
+// directory, the clock, the runner for external commands, and terminal information.
 type Container interface {
 	EnvContainer
 	StdinContainer
 	StdoutContainer
 	StderrContainer
 	ArgContainer
+	FileSystemContainer
+	WorkDirContainer
+	ClockContainer
+	RunnerContainer
+	TerminalContainer
 }
 
 // NewContainer returns a new Container.
+//
+// The Container uses a new in-memory FileSystem, the working directory ".", the
+// Clock for the operating system, a Runner that returns an error for every command,
+// and never considers stdio to be a terminal. Use NewContainerForFileSystem,
+// NewContainerForWorkDir, NewContainerForClock, NewContainerForRunner, and
+// NewContainerForTerminal to replace these.
 func NewContainer(
 	env map[string]string,
 	stdin io.Reader,
@@ -173,6 +558,11 @@ func NewContainer(
 		NewStdoutContainer(stdout),
 		NewStderrContainer(stderr),
 		NewArgContainer(args...),
+		NewFileSystemContainer(nil),
+		NewWorkDirContainer(""),
+		NewClockContainerForOS(),
+		NewRunnerContainer(nil),
+		NewTerminalContainer(false, false, false, 0, 0),
 	)
 }
 
@@ -182,12 +572,21 @@ func NewContainerForOS() (Container, error) {
 	if err != nil {
 		return nil, err
 	}
+	workDirContainer, err := NewWorkDirContainerForOS()
+	if err != nil {
+		return nil, err
+	}
 	return newContainer(
 		envContainer,
 		NewStdinContainerForOS(),
 		NewStdoutContainerForOS(),
 		NewStderrContainerForOS(),
 		NewArgContainerForOS(),
+		NewFileSystemContainerForOS(),
+		workDirContainer,
+		NewClockContainerForOS(),
+		NewRunnerContainerForOS(),
+		NewTerminalContainerForOS(),
 	), nil
 }
 
@@ -199,6 +598,107 @@ func NewContainerForArgs(container Container, newArgs ...string) Container {
 		container,
 		container,
 		NewArgContainer(newArgs...),
+		container,
+		container,
+		container,
+		container,
+		container,
+	)
+}
+
+// NewContainerForFileSystem returns a new Container with the replacement FileSystem.
+func NewContainerForFileSystem(container Container, fileSystem FileSystem) Container {
+	return newContainer(
+		container,
+		container,
+		container,
+		container,
+		container,
+		NewFileSystemContainer(fileSystem),
+		container,
+		container,
+		container,
+		container,
+	)
+}
+
+// NewContainerForEnv returns a new Container with the replacement EnvContainer.
+func NewContainerForEnv(container Container, envContainer EnvContainer) Container {
+	return newContainer(
+		envContainer,
+		container,
+		container,
+		container,
+		container,
+		container,
+		container,
+		container,
+		container,
+		container,
+	)
+}
+
+// NewContainerForWorkDir returns a new Container with the replacement working directory path.
+func NewContainerForWorkDir(container Container, workDirPath string) Container {
+	return newContainer(
+		container,
+		container,
+		container,
+		container,
+		container,
+		container,
+		NewWorkDirContainer(workDirPath),
+		container,
+		container,
+		container,
+	)
+}
+
+// NewContainerForClock returns a new Container with the replacement Clock.
+func NewContainerForClock(container Container, clock Clock) Container {
+	return newContainer(
+		container,
+		container,
+		container,
+		container,
+		container,
+		container,
+		container,
+		NewClockContainer(clock),
+		container,
+		container,
+	)
+}
+
+// NewContainerForRunner returns a new Container with the replacement Runner.
+func NewContainerForRunner(container Container, runner Runner) Container {
+	return newContainer(
+		container,
+		container,
+		container,
+		container,
+		container,
+		container,
+		container,
+		container,
+		NewRunnerContainer(runner),
+		container,
+	)
+}
+
+// NewContainerForTerminal returns a new Container with the replacement TerminalContainer.
+func NewContainerForTerminal(container Container, terminalContainer TerminalContainer) Container {
+	return newContainer(
+		container,
+		container,
+		container,
+		container,
+		container,
+		container,
+		container,
+		container,
+		container,
+		terminalContainer,
 	)
 }
 
@@ -276,12 +776,277 @@ func Args(argList ArgContainer) []string {
 // EnvBool EnvBoolValue gets and parses the environment variable bool value for the key.
 //
 // Returns error on parsing error.
+// Parsing errors name the environment variable but never include its value.
 func EnvBool(container EnvContainer, key string, defaultValue bool) (bool, error) {
 	value := container.Env(key)
 	if value == "" {
 		return defaultValue, nil
 	}
-	return strconv.ParseBool(value)
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return defaultValue, newEnvValueError(key, "a boolean")
+	}
+	return parsed, nil
+}
+
+// EnvInt gets and parses the environment variable int value for the key.
+//
+// Returns error on parsing error.
+// Parsing errors name the environment variable but never include its value.
+func EnvInt(container EnvContainer, key string, defaultValue int) (int, error) {
+	value := container.Env(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+	parsed, err := strconv.ParseInt(value, 10, strconv.IntSize)
+	if err != nil {
+		return defaultValue, newEnvNumError(key, "an integer", err)
+	}
+	return int(parsed), nil
+}
+
+// EnvUint gets and parses the environment variable uint value for the key.
+//
+// Returns error on parsing error.
+// Parsing errors name the environment variable but never include its value.
+func EnvUint(container EnvContainer, key string, defaultValue uint) (uint, error) {
+	value := container.Env(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+	parsed, err := strconv.ParseUint(value, 10, strconv.IntSize)
+	if err != nil {
+		return defaultValue, newEnvNumError(key, "a non-negative integer", err)
+	}
+	return uint(parsed), nil
+}
+
+// EnvFloat gets and parses the environment variable float64 value for the key.
+//
+// Returns error on parsing error.
+// Parsing errors name the environment variable but never include its value.
+func EnvFloat(container EnvContainer, key string, defaultValue float64) (float64, error) {
+	value := container.Env(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return defaultValue, newEnvNumError(key, "a number", err)
+	}
+	return parsed, nil
+}
+
+// EnvDuration gets and parses the environment variable time.Duration value for the key.
+//
+// The value is parsed with time.ParseDuration, for example "1m30s".
+//
+// Returns error on parsing error.
+// Parsing errors name the environment variable but never include its value.
+func EnvDuration(container EnvContainer, key string, defaultValue time.Duration) (time.Duration, error) {
+	value := container.Env(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return defaultValue, newEnvValueError(key, "a duration such as 1m30s")
+	}
+	return parsed, nil
+}
+
+// EnvStringSlice gets the environment variable value for the key split by the separator.
+//
+// Each element is trimmed of surrounding whitespace, and empty elements are dropped.
+// If the environment variable is not set, or contains no non-empty elements, the defaultValue is returned.
+func EnvStringSlice(container EnvContainer, key string, separator string, defaultValue []string) []string {
+	value := container.Env(key)
+	if value == "" {
+		return defaultValue
+	}
+	var elements []string
+	for _, element := range strings.Split(value, separator) {
+		if element = strings.TrimSpace(element); element != "" {
+			elements = append(elements, element)
+		}
+	}
+	if len(elements) == 0 {
+		return defaultValue
+	}
+	return elements
+}
+
+// EnvEnum gets the environment variable value for the key and checks that it is one of the allowedValues.
+//
+// Returns error if the value is not one of the allowedValues.
+// The error names the environment variable and the allowed values but never includes the value.
+func EnvEnum[T ~string](container EnvContainer, key string, defaultValue T, allowedValues ...T) (T, error) {
+	value := container.Env(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+	for _, allowedValue := range allowedValues {
+		if value == string(allowedValue) {
+			return allowedValue, nil
+		}
+	}
+	allowedStrings := make([]string, len(allowedValues))
+	for i, allowedValue := range allowedValues {
+		allowedStrings[i] = string(allowedValue)
+	}
+	return defaultValue, newEnvValueError(key, "one of ["+strings.Join(allowedStrings, ", ")+"]")
+}
+
+// EnvURL gets and parses the environment variable URL value for the key.
+//
+// The URL must be absolute, that is it must have a scheme.
+//
+// Returns error on parsing error.
+// Parsing errors name the environment variable but never include its value, as URLs
+// frequently contain credentials.
+func EnvURL(container EnvContainer, key string, defaultValue *url.URL) (*url.URL, error) {
+	value := container.Env(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+	parsed, err := url.Parse(value)
+	if err != nil || !parsed.IsAbs() {
+		return defaultValue, newEnvValueError(key, "an absolute URL")
+	}
+	return parsed, nil
+}
+
+// BindEnv populates the struct pointed to by value from environment variables.
+//
+// Fields are bound using struct tags:
+//
+//   - `env:"NAME"` binds the field to the environment variable NAME.
+//     `env:"NAME,required"` additionally returns an error if NAME is not set.
+//   - `envDefault:"VALUE"` sets the field to VALUE if the environment variable is not set.
+//   - `envPrefix:"PREFIX_"` on a struct or struct pointer field binds the fields of the nested
+//     struct with PREFIX_ prepended to their names. Embedded structs are bound without a prefix.
+//     A nil struct pointer field is only allocated if any of the environment variables of the
+//     nested struct are set, in which case its required environment variables must be set.
+//   - `envSeparator:";"` sets the separator for slice fields. The default is ",".
+//
+// Supported field types are strings, bools, ints, uints, floats, time.Duration, url.URL
+// (which must be absolute), types implementing encoding.TextUnmarshaler, and pointers and
+// slices of these. Fields that are not set and have no default are left unchanged.
+//
+// All missing and invalid environment variables are reported in a single error joined with
+// errors.Join. Errors name the environment variables but never include their values.
+func BindEnv(container EnvContainer, value any, options ...BindEnvOption) error {
+	bindEnvOptions := newBindEnvOptions()
+	for _, option := range options {
+		option(bindEnvOptions)
+	}
+	return bindEnv(container, value, bindEnvOptions)
+}
+
+// BindEnvOption is an option for BindEnv.
+type BindEnvOption func(*bindEnvOptions)
+
+// BindEnvWithPrefix returns a new BindEnvOption that prepends the prefix to all environment variable names.
+func BindEnvWithPrefix(prefix string) BindEnvOption {
+	return func(bindEnvOptions *bindEnvOptions) {
+		bindEnvOptions.prefix = prefix
+	}
+}
+
+// Exec runs the external program with the given name and args using the Runner of the container.
+//
+// By default, the program inherits the environment, stdio, and working directory of the
+// container. These can be changed with ExecOptions.
+//
+// If the program exits with a non-zero exit code, the returned error contains the exit
+// code, which can be retrieved with GetExitCode.
+func Exec(ctx context.Context, container Container, name string, args []string, options ...ExecOption) error {
+	execOptions := newExecOptions()
+	for _, option := range options {
+		option(execOptions)
+	}
+	return execCommand(ctx, container, name, args, execOptions)
+}
+
+// ExecOption is an option for Exec.
+type ExecOption func(*execOptions)
+
+// ExecWithEnvOverrides returns a new ExecOption that overrides the environment of the
+// container for the program.
+//
+// To unset a key, set the value to "".
+func ExecWithEnvOverrides(overrides map[string]string) ExecOption {
+	return func(execOptions *execOptions) {
+		execOptions.envOverrides = overrides
+	}
+}
+
+// ExecWithDir returns a new ExecOption that runs the program in the given directory.
+//
+// If the directory is relative, it is resolved against the working directory of the container.
+func ExecWithDir(dirPath string) ExecOption {
+	return func(execOptions *execOptions) {
+		execOptions.dirPath = dirPath
+	}
+}
+
+// ExecWithStdin returns a new ExecOption that uses the given stdin for the program
+// instead of the stdin of the container.
+func ExecWithStdin(stdin io.Reader) ExecOption {
+	return func(execOptions *execOptions) {
+		execOptions.stdin = stdin
+	}
+}
+
+// ExecWithStdout returns a new ExecOption that uses the given stdout for the program
+// instead of the stdout of the container.
+func ExecWithStdout(stdout io.Writer) ExecOption {
+	return func(execOptions *execOptions) {
+		execOptions.stdout = stdout
+	}
+}
+
+// ExecWithStderr returns a new ExecOption that uses the given stderr for the program
+// instead of the stderr of the container.
+func ExecWithStderr(stderr io.Writer) ExecOption {
+	return func(execOptions *execOptions) {
+		execOptions.stderr = stderr
+	}
+}
+
+// ContextWithTimeout is equivalent to context.WithTimeout, but uses the Clock
+// to determine when the timeout has elapsed.
+//
+// If the Clock is not the real clock, the timeout is not reported by the Deadline method
+// of the returned context, as consumers such as net/http and net.Dialer compare the
+// deadline against the real time. Deadline then returns the deadline of ctx, if any.
+func ContextWithTimeout(ctx context.Context, clock Clock, timeout time.Duration) (context.Context, context.CancelFunc) {
+	return contextWithClockTimeout(ctx, clock, timeout)
+}
+
+// ResolvePath resolves the path against the working directory of the container.
+//
+// If the path is absolute, the cleaned path is returned.
+// If the path is empty or a device path per IsDevPath, the path is returned unchanged.
+// Otherwise, the path is joined to the working directory path.
+//
+// This should be used instead of filepath.Abs, which uses the working directory of the process.
+func ResolvePath(workDirContainer WorkDirContainer, path string) string {
+	if path == "" || IsDevPath(path) {
+		return path
+	}
+	if filepath.IsAbs(path) {
+		return filepath.Clean(path)
+	}
+	return filepath.Join(workDirContainer.WorkDirPath(), path)
+}
+
+// ResolveArgPath resolves the ith argument as a path against the working directory of
+// the container, per ResolvePath.
+//
+// Panics if i < 0 || i >= NumArgs().
+func ResolveArgPath(container Container, i int) string {
+	return ResolvePath(container, container.Arg(i))
 }
 
 // IsDevStdin returns true if the path is the equivalent of /dev/stdin.
@@ -311,27 +1076,67 @@ func IsDevPath(path string) bool {
 }
 
 // Main runs the application using the OS Container and calling os.Exit on the return value of Run.
-func Main(ctx context.Context, f func(context.Context, Container) error) {
+func Main(ctx context.Context, f func(context.Context, Container) error, options ...RunOption) {
 	container, err := NewContainerForOS()
 	if err != nil {
 		printError(container, err)
 		os.Exit(GetExitCode(err))
 	}
-	os.Exit(GetExitCode(Run(ctx, container, f)))
+	os.Exit(GetExitCode(Run(ctx, container, f, options...)))
 }
 
 // Run runs the application using the container.
 //
 // The run will be stopped on app signal.
 // The exit code can be determined using GetExitCode.
-func Run(ctx context.Context, container Container, f func(context.Context, Container) error) error {
-	if err := f(interrupt.Handle(ctx), container); err != nil {
+func Run(ctx context.Context, container Container, f func(context.Context, Container) error, options ...RunOption) error {
+	runOptions := newRunOptions()
+	for _, option := range options {
+		option(runOptions)
+	}
+	if err := runFunc(interrupt.Handle(ctx), container, f, runOptions); err != nil {
 		printError(container, err)
 		return err
 	}
 	return nil
 }
 
+// RunOption is an option for Run and Main.
+type RunOption func(*runOptions)
+
+// RunWithRecoverPanics returns a new RunOption that recovers panics in the run function.
+//
+// When a panic is recovered, Run prints a short message to stderr and returns an error
+// with the exit code ExitCodePanic.
+//
+// If getCrashDirPath is not nil and returns a non-empty path, a crash report is also written
+// to a new file in this directory using the FileSystem of the container. The crash report
+// contains the panic value, the stack, the version, the args, and the names of the
+// environment variables. The values of the environment variables are never included, and
+// the values of flags with names that match DefaultRedactedEnvKeyPatterns, such as
+// --api-token, are redacted.
+func RunWithRecoverPanics(getCrashDirPath func(Container) string) RunOption {
+	return func(runOptions *runOptions) {
+		runOptions.recoverPanics = true
+		runOptions.getCrashDirPath = getCrashDirPath
+	}
+}
+
+// RunWithVersion returns a new RunOption that sets the version of the application.
+//
+// This is included in crash reports. The default is the version of the main module
+// from the build info, if available.
+func RunWithVersion(version string) RunOption {
+	return func(runOptions *runOptions) {
+		runOptions.version = version
+	}
+}
+
+// ExitCodePanic is the exit code returned when a panic is recovered by Run.
+//
+// See RunWithRecoverPanics. This matches EX_SOFTWARE from sysexits.h.
+const ExitCodePanic = 70
+
 // NewError returns a new Error that contains an exit code.
 //
 // The exit code cannot be 0.
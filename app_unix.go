@@ -109,6 +109,70 @@ func DataDirPath(envContainer EnvContainer) (string, error) {
 	return xdgDirPath(envContainer, "XDG_DATA_HOME", filepath.Join(".local", "share"))
 }
 
+// StateDirPath returns the state directory path.
+//
+// This will be $XDG_STATE_HOME for darwin and linux, falling back to $HOME/.local/state.
+// This will be %LocalAppData% for windows.
+//
+// See https://specifications.freedesktop.org/basedir-spec/basedir-spec-latest.html
+// for darwin and linux.
+//
+// Users cannot assume that StateDirPath is unique from the other directory paths.
+func StateDirPath(envContainer EnvContainer) (string, error) {
+	return xdgDirPath(envContainer, "XDG_STATE_HOME", filepath.Join(".local", "state"))
+}
+
+// RuntimeDirPath returns the runtime directory path, for sockets, PID files, and the like.
+//
+// This will be $XDG_RUNTIME_DIR for darwin and linux. There is no fallback, as the
+// directory has specific ownership and lifetime requirements.
+// This will be %TEMP% for windows.
+//
+// See https://specifications.freedesktop.org/basedir-spec/basedir-spec-latest.html
+// for darwin and linux.
+func RuntimeDirPath(envContainer EnvContainer) (string, error) {
+	if value := envContainer.Env("XDG_RUNTIME_DIR"); value != "" {
+		return value, nil
+	}
+	return "", errors.New("$XDG_RUNTIME_DIR is not set")
+}
+
+// SystemConfigDirPaths returns the system-wide config directory paths, in order of precedence.
+//
+// This will be $XDG_CONFIG_DIRS for darwin and linux, falling back to /etc/xdg.
+// This will be %ProgramData% for windows, if set.
+//
+// See https://specifications.freedesktop.org/basedir-spec/basedir-spec-latest.html
+// for darwin and linux. Relative paths are ignored.
+func SystemConfigDirPaths(envContainer EnvContainer) []string {
+	return xdgDirPaths(envContainer, "XDG_CONFIG_DIRS", "/etc/xdg")
+}
+
+// SystemDataDirPaths returns the system-wide data directory paths, in order of precedence.
+//
+// This will be $XDG_DATA_DIRS for darwin and linux, falling back to /usr/local/share and /usr/share.
+// This will be %ProgramData% for windows, if set.
+//
+// See https://specifications.freedesktop.org/basedir-spec/basedir-spec-latest.html
+// for darwin and linux. Relative paths are ignored.
+func SystemDataDirPaths(envContainer EnvContainer) []string {
+	return xdgDirPaths(envContainer, "XDG_DATA_DIRS", "/usr/local/share", "/usr/share")
+}
+
+func xdgDirPaths(envContainer EnvContainer, key string, fallbackDirPaths ...string) []string {
+	var dirPaths []string
+	for _, dirPath := range filepath.SplitList(envContainer.Env(key)) {
+		// The specification says relative paths are invalid and should be ignored.
+		if filepath.IsAbs(dirPath) {
+			dirPaths = append(dirPaths, dirPath)
+		}
+	}
+	if len(dirPaths) == 0 {
+		return fallbackDirPaths
+	}
+	return dirPaths
+}
+
 func xdgDirPath(envContainer EnvContainer, key string, fallbackRelHomeDirPath string) (string, error) {
 	if value := envContainer.Env(key); value != "" {
 		return value, nil
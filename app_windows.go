@@ -107,3 +107,63 @@ func DataDirPath(envContainer EnvContainer) (string, error) {
 	}
 	return "", errors.New("%LocalAppData% is not set")
 }
+
+// StateDirPath returns the state directory path.
+//
+// This will be $XDG_STATE_HOME for darwin and linux, falling back to $HOME/.local/state.
+// This will be %LocalAppData% for windows.
+//
+// See https://specifications.freedesktop.org/basedir-spec/basedir-spec-latest.html
+// for darwin and linux.
+//
+// Users cannot assume that StateDirPath is unique from the other directory paths.
+func StateDirPath(envContainer EnvContainer) (string, error) {
+	if value := envContainer.Env("LOCALAPPDATA"); value != "" {
+		return value, nil
+	}
+	return "", errors.New("%LocalAppData% is not set")
+}
+
+// RuntimeDirPath returns the runtime directory path, for sockets, PID files, and the like.
+//
+// This will be $XDG_RUNTIME_DIR for darwin and linux. There is no fallback, as the
+// directory has specific ownership and lifetime requirements.
+// This will be %TEMP% for windows.
+//
+// See https://specifications.freedesktop.org/basedir-spec/basedir-spec-latest.html
+// for darwin and linux.
+func RuntimeDirPath(envContainer EnvContainer) (string, error) {
+	if value := envContainer.Env("TEMP"); value != "" {
+		return value, nil
+	}
+	return "", errors.New("%TEMP% is not set")
+}
+
+// SystemConfigDirPaths returns the system-wide config directory paths, in order of precedence.
+//
+// This will be $XDG_CONFIG_DIRS for darwin and linux, falling back to /etc/xdg.
+// This will be %ProgramData% for windows, if set.
+//
+// See https://specifications.freedesktop.org/basedir-spec/basedir-spec-latest.html
+// for darwin and linux. Relative paths are ignored.
+func SystemConfigDirPaths(envContainer EnvContainer) []string {
+	return programDataDirPaths(envContainer)
+}
+
+// SystemDataDirPaths returns the system-wide data directory paths, in order of precedence.
+//
+// This will be $XDG_DATA_DIRS for darwin and linux, falling back to /usr/local/share and /usr/share.
+// This will be %ProgramData% for windows, if set.
+//
+// See https://specifications.freedesktop.org/basedir-spec/basedir-spec-latest.html
+// for darwin and linux. Relative paths are ignored.
+func SystemDataDirPaths(envContainer EnvContainer) []string {
+	return programDataDirPaths(envContainer)
+}
+
+func programDataDirPaths(envContainer EnvContainer) []string {
+	if value := envContainer.Env("PROGRAMDATA"); value != "" {
+		return []string{value}
+	}
+	return nil
+}
@@ -100,13 +100,16 @@ func WrapInvalidArgumentError(delegate error) error {
 }
 
 // Main runs the application using the OS container and calling os.Exit on the return value of Run.
-func Main(ctx context.Context, command *Command) {
-	app.Main(ctx, newRunFunc(command))
+func Main(ctx context.Context, command *Command, options ...app.RunOption) {
+	app.Main(ctx, newRunFunc(command), withCommandVersion(command, options)...)
 }
 
 // Run runs the application using the container.
-func Run(ctx context.Context, container app.Container, command *Command) error {
-	return app.Run(ctx, container, newRunFunc(command))
+//
+// If the command has a version, this is used as the version for app.RunWithVersion
+// unless the options set a version.
+func Run(ctx context.Context, container app.Container, command *Command, options ...app.RunOption) error {
+	return app.Run(ctx, container, newRunFunc(command), withCommandVersion(command, options)...)
 }
 
 // BindMultiple is a convenience function for binding multiple flag functions.
@@ -132,6 +135,15 @@ func newRunFunc(command *Command) func(context.Context, app.Container) error {
 	}
 }
 
+// withCommandVersion prepends app.RunWithVersion with the version of the command, so that
+// any version set by the given options takes precedence.
+func withCommandVersion(command *Command, options []app.RunOption) []app.RunOption {
+	if command.Version == "" {
+		return options
+	}
+	return append([]app.RunOption{app.RunWithVersion(command.Version)}, options...)
+}
+
 func run(
 	ctx context.Context,
 	container app.Container,
@@ -226,6 +238,12 @@ func run(
 		cobraCommand.AddCommand(manpagesCobraCommand)
 	}
 
+	// Wrap flag usages to the width of the terminal, if known.
+	// This is inherited by all sub-commands.
+	if width, _ := container.TerminalSize(); width > 0 {
+		cobraCommand.SetUsageTemplate(wrappedUsageTemplate(cobraCommand.UsageTemplate(), width))
+	}
+
 	cobraCommand.SetOut(container.Stderr())
 	args := app.Args(container)[1:]
 	// cobra will implicitly create __complete and __completeNoDesc subcommands
@@ -22,6 +22,7 @@ import (
 	"testing"
 
 	"buf.build/go/app"
+	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -226,3 +227,40 @@ func TestIncorrectFlagEmptyStdout(t *testing.T) {
 	require.Empty(t, stdout.String())
 	require.NotEmpty(t, stderr.String())
 }
+
+func TestHelpWrappedToTerminalWidth(t *testing.T) {
+	t.Parallel()
+	rootCommand := &Command{
+		Use: "test",
+		BindFlags: func(flagSet *pflag.FlagSet) {
+			flagSet.String("foo", "", strings.Repeat("word ", 20))
+		},
+		Run: func(context.Context, app.Container) error {
+			return nil
+		},
+	}
+	testHelpMaxLineLength := func(width int) int {
+		buffer := bytes.NewBuffer(nil)
+		container := app.NewContainerForTerminal(
+			app.NewContainer(nil, nil, buffer, nil, "test", "--help"),
+			app.NewTerminalContainer(false, true, true, width, 24),
+		)
+		require.NoError(t, Run(context.Background(), container, rootCommand))
+		var maxLineLength int
+		for line := range strings.SplitSeq(buffer.String(), "\n") {
+			maxLineLength = max(maxLineLength, len(line))
+		}
+		return maxLineLength
+	}
+	assert.Greater(t, testHelpMaxLineLength(0), 60)
+	assert.LessOrEqual(t, testHelpMaxLineLength(60), 60)
+}
+
+func TestWrappedUsageTemplate(t *testing.T) {
+	t.Parallel()
+	usageTemplate := (&cobra.Command{}).UsageTemplate()
+	wrappedTemplate := wrappedUsageTemplate(usageTemplate, 60)
+	assert.Contains(t, wrappedTemplate, ".LocalFlags.FlagUsagesWrapped 60")
+	assert.Contains(t, wrappedTemplate, ".InheritedFlags.FlagUsagesWrapped 60")
+	assert.NotRegexp(t, `\.FlagUsages\b`, wrappedTemplate)
+}
@@ -66,16 +66,33 @@ func Run(
 		env = runOptions.newEnv(testingUse)
 	}
 
+	container := app.NewContainer(
+		env,
+		stdin,
+		stdout,
+		stderr,
+		append([]string{testingUse}, runOptions.args...)...,
+	)
+	if runOptions.fileSystem != nil {
+		container = app.NewContainerForFileSystem(container, runOptions.fileSystem)
+	}
+	if runOptions.workDirPath != "" {
+		container = app.NewContainerForWorkDir(container, runOptions.workDirPath)
+	}
+	if runOptions.clock != nil {
+		container = app.NewContainerForClock(container, runOptions.clock)
+	}
+	if runOptions.runner != nil {
+		container = app.NewContainerForRunner(container, runOptions.runner)
+	}
+	if runOptions.terminalContainer != nil {
+		container = app.NewContainerForTerminal(container, runOptions.terminalContainer)
+	}
+
 	exitCode := app.GetExitCode(
 		appcmd.Run(
 			context.Background(),
-			app.NewContainer(
-				env,
-				stdin,
-				stdout,
-				stderr,
-				append([]string{testingUse}, runOptions.args...)...,
-			),
+			container,
 			newCommand(testingUse),
 		),
 	)
@@ -183,6 +200,53 @@ func WithStderr(stderr io.Writer) RunOption {
 	}
 }
 
+// WithFileSystem will use the given FileSystem.
+//
+// The default is a new in-memory FileSystem.
+func WithFileSystem(fileSystem app.FileSystem) RunOption {
+	return func(runOptions *runOptions) {
+		runOptions.fileSystem = fileSystem
+	}
+}
+
+// WithWorkDir will use the given working directory path.
+//
+// The default is ".".
+func WithWorkDir(workDirPath string) RunOption {
+	return func(runOptions *runOptions) {
+		runOptions.workDirPath = workDirPath
+	}
+}
+
+// WithClock will use the given Clock.
+//
+// The default is the Clock for the operating system. Use app.NewFakeClock to control time.
+func WithClock(clock app.Clock) RunOption {
+	return func(runOptions *runOptions) {
+		runOptions.clock = clock
+	}
+}
+
+// WithRunner will use the given Runner for external commands.
+//
+// The default is a Runner that returns an error for every command. Use app.NewFakeRunner
+// to fake external commands.
+func WithRunner(runner app.Runner) RunOption {
+	return func(runOptions *runOptions) {
+		runOptions.runner = runner
+	}
+}
+
+// WithTerminal will use the given TerminalContainer.
+//
+// The default is to never consider stdio to be a terminal. Use app.NewTerminalContainer
+// to force values.
+func WithTerminal(terminalContainer app.TerminalContainer) RunOption {
+	return func(runOptions *runOptions) {
+		runOptions.terminalContainer = terminalContainer
+	}
+}
+
 // WithArgs adds the given args.
 func WithArgs(args ...string) RunOption {
 	return func(runOptions *runOptions) {
@@ -279,6 +343,11 @@ type runOptions struct {
 	stdin                         io.Reader
 	stdout                        io.Writer
 	stderr                        io.Writer
+	fileSystem                    app.FileSystem
+	workDirPath                   string
+	clock                         app.Clock
+	runner                        app.Runner
+	terminalContainer             app.TerminalContainer
 	args                          []string
 	expectedStdout                string
 	expectedStdoutPresent         bool
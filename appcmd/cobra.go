@@ -17,6 +17,8 @@ package appcmd
 import (
 	"fmt"
 	"io"
+	"regexp"
+	"strconv"
 	"strings"
 	"text/template"
 	"unicode"
@@ -36,6 +38,16 @@ var templateFuncs = template.FuncMap{
 	"eq":                      cobra.Eq,
 }
 
+// flagUsagesRegexp matches calls of FlagUsages in a usage template.
+var flagUsagesRegexp = regexp.MustCompile(`\.FlagUsages\b`)
+
+// wrappedUsageTemplate returns the usage template with the flag usages wrapped to the given width.
+//
+// The usage template is the template of cobra, so that changes to it in cobra are picked up.
+func wrappedUsageTemplate(usageTemplate string, width int) string {
+	return flagUsagesRegexp.ReplaceAllString(usageTemplate, ".FlagUsagesWrapped "+strconv.Itoa(width))
+}
+
 func trimRightSpace(s string) string {
 	return strings.TrimRightFunc(s, unicode.IsSpace)
 }
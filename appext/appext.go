@@ -24,16 +24,23 @@ import (
 	"net"
 	"os"
 	"path/filepath"
+	"reflect"
 	"time"
 
 	"buf.build/go/app"
+	"buf.build/go/app/appcmd"
 	"github.com/spf13/pflag"
 	"gopkg.in/yaml.v3"
 )
 
 const (
-	configFileName   = "config.yaml"
-	secretRelDirPath = "secrets"
+	configFileBaseName = "config"
+	configLockFileName = ".config.lock"
+	secretRelDirPath   = "secrets"
+	crashRelDirPath    = "crash"
+	dotenvFileName     = ".env"
+	profileEnvSuffix   = "PROFILE"
+	configProfilesKey  = "profiles"
 )
 
 // NameContainer is a container for named applications.
@@ -65,6 +72,39 @@ type NameContainer interface {
 	// If this is not set, uses app.DataDirPath()/app-name.
 	// Unnormalized.
 	DataDirPath() string
+	// StateDirPath is the state directory path for the named application.
+	//
+	// First checks for $APP_NAME_STATE_DIR.
+	// If this is not set, uses app.StateDirPath()/app-name.
+	// Unnormalized.
+	StateDirPath() string
+	// RuntimeDirPath is the runtime directory path for the named application.
+	//
+	// First checks for $APP_NAME_RUNTIME_DIR.
+	// If this is not set, uses app.RuntimeDirPath()/app-name.
+	// This is empty if neither is available, in which case there is no runtime directory.
+	// Unnormalized.
+	RuntimeDirPath() string
+	// ConfigDirPaths are the config directory paths to search for the named application,
+	// in order of precedence.
+	//
+	// The first path is ConfigDirPath, if not empty.
+	// The remaining paths are app.SystemConfigDirPaths()/app-name.
+	// Unnormalized.
+	ConfigDirPaths() []string
+	// DataDirPaths are the data directory paths to search for the named application,
+	// in order of precedence.
+	//
+	// The first path is DataDirPath, if not empty.
+	// The remaining paths are app.SystemDataDirPaths()/app-name.
+	// Unnormalized.
+	DataDirPaths() []string
+	// Profile is the configuration profile to use for the named application.
+	//
+	// This is $APP_NAME_PROFILE. If this is not set, returns empty, which means only the
+	// base section of configuration files is used.
+	// See LoadConfig for how profiles are applied.
+	Profile() string
 	// Port is the port to use for serving.
 	//
 	// First checks for $APP_NAME_PORT.
@@ -159,6 +199,7 @@ type BuilderOption func(*builder)
 // BuilderWithTimeout returns a new BuilderOption that adds a timeout flag with the default timeout.
 //
 // If defaultTimeout is 0, no timeout will be used by default, but the flag will exist.
+// The timeout is measured using the Clock of the container.
 func BuilderWithTimeout(defaultTimeout time.Duration) BuilderOption {
 	return func(builder *builder) {
 		builder.defaultTimeout = defaultTimeout
@@ -166,6 +207,18 @@ func BuilderWithTimeout(defaultTimeout time.Duration) BuilderOption {
 	}
 }
 
+// BuilderWithProfile returns a new BuilderOption that adds a profile flag.
+//
+// The flag selects the configuration profile, as NameContainer.Profile does for
+// $APP_NAME_PROFILE, and takes precedence over the environment variable. The environment
+// variable is set to the flag value in the Container, so that child processes use the
+// same profile.
+func BuilderWithProfile() BuilderOption {
+	return func(builder *builder) {
+		builder.profileFlag = true
+	}
+}
+
 // BuilderWithInterceptor adds the given interceptor for all run functions.
 func BuilderWithInterceptor(interceptor Interceptor) BuilderOption {
 	return func(builder *builder) {
@@ -178,99 +231,1145 @@ type LoggerProvider func(NameContainer, LogLevel, LogFormat) (*slog.Logger, erro
 
 // BuilderWithLoggerProvider overrides the default LoggerProvider.
 //
-// The default is to use slogbuild.
+// The default uses slog.TextHandler for LogFormatText, slog.JSONHandler for LogFormatJSON,
+// and a human-oriented handler for LogFormatColor.
 func BuilderWithLoggerProvider(loggerProvider LoggerProvider) BuilderOption {
 	return func(builder *builder) {
 		builder.loggerProvider = loggerProvider
 	}
 }
 
-// ReadConfig reads the configuration from the YAML configuration file config.yaml
-// in the configuration directory.
+// BuilderWithDotenv returns a new BuilderOption that loads environment variables from .env files.
 //
-// If the file does not exist, this is a no-op.
+// The files .env in the working directory and .env in the configuration directory are loaded,
+// if they exist, using app.NewEnvContainerForDotenv. The environment takes precedence over the
+// file in the working directory, which takes precedence over the file in the configuration
+// directory. The configuration directory is determined from the environment before any file
+// is loaded. The files are read from the FileSystem of the container.
+func BuilderWithDotenv() BuilderOption {
+	return func(builder *builder) {
+		builder.dotenv = true
+	}
+}
+
+// BuilderWithPIDFile returns a new BuilderOption that makes Listen write a PID file, so that
+// only a single instance of the application runs at a time.
+//
+// The PID file is RuntimeDirPath/app-name.pid, or DataDirPath/app-name.pid if there is no
+// runtime directory, and contains the PID of the process. Listen locks the PID file for the
+// lifetime of the process with app.FileSystem.TryLock, and returns an error if it is already
+// locked by a running instance. The lock is released by the operating system when the process
+// exits, even if it crashes, so a PID file that is left behind is not locked and is overwritten.
+// The PID file is removed when the command returns, including when the command was interrupted.
+//
+// The PID file is written by Listen instead of for every command, so that the other commands
+// of the application, such as those of NewInstanceCommand, can run while an instance is running.
+func BuilderWithPIDFile() BuilderOption {
+	return func(builder *builder) {
+		builder.pidFile = true
+	}
+}
+
+// BindEnv populates the struct pointed to by value from environment variables.
+//
+// This is app.BindEnv with the names in the env tags prefixed with the environment variable
+// prefix of the application, for example `env:"PORT"` is bound to APP_NAME_PORT.
+func BindEnv(container NameContainer, value any) error {
+	return app.BindEnv(container, value, app.BindEnvWithPrefix(getAppNameEnvPrefix(container.AppName())))
+}
+
+// RunWithRecoverPanics returns a new app.RunOption that recovers panics and writes
+// crash reports to the crash subdirectory of the cache directory for the named application.
+//
+// See app.RunWithRecoverPanics for details.
+func RunWithRecoverPanics(appName string) app.RunOption {
+	return app.RunWithRecoverPanics(
+		func(container app.Container) string {
+			nameContainer, err := NewNameContainer(container, appName)
+			if err != nil {
+				return ""
+			}
+			return filepath.Join(nameContainer.CacheDirPath(), crashRelDirPath)
+		},
+	)
+}
+
+// ReadConfig reads the configuration from the configuration file in the configuration directory.
+//
+// The configuration file is config.yaml, or config.json or config.toml, or config with the file
+// extension of any ConfigCodec given with ConfigWithCodecs. It is an error if more than one
+// configuration file exists in the same directory.
+//
+// The directories in ConfigDirPaths are searched in order, and the first file found is read,
+// so that system-wide configuration files are used if there is no user configuration file.
+// The file is read from the FileSystem of the container.
+// If no file exists, this is a no-op.
+// If a profile is selected, it is merged over the base section of the file, and it is an
+// error if the file does not contain the profile. See LoadConfig.
 // The value should be a pointer to unmarshal into.
-func ReadConfig(container NameContainer, value any) error {
-	configFilePath := filepath.Join(container.ConfigDirPath(), configFileName)
-	data, err := os.ReadFile(configFilePath)
-	if !errors.Is(err, os.ErrNotExist) {
-		if err != nil {
-			return fmt.Errorf("could not read %s configuration file at %s: %w", container.AppName(), configFilePath, err)
+func ReadConfig(container NameContainer, value any, options ...ConfigOption) error {
+	return readConfig(container, value, true, options...)
+}
+
+// ReadConfigNonStrict reads the configuration from the configuration file in the
+// configuration directory, ignoring any unknown properties.
+//
+// The configuration file is found in the same manner as ReadConfig.
+// The file is read from the FileSystem of the container.
+// If no file exists, this is a no-op.
+// The value should be a pointer to unmarshal into.
+func ReadConfigNonStrict(container NameContainer, value any, options ...ConfigOption) error {
+	return readConfig(container, value, false, options...)
+}
+
+// ReadSecret returns the secret with the name.
+//
+// The secret is resolved from the first of the following that is set:
+//
+//   - $APP_NAME_SECRET_NAME, where NAME is the name in upper case with "-" replaced by "_".
+//   - The file at $APP_NAME_SECRET_NAME_FILE, following the Docker and Kubernetes convention
+//     for mounted secrets. Relative paths are resolved against the working directory.
+//   - The file filepath.Join(container.ConfigDirPath(), "secrets", name), as written by WriteSecret.
+//     On unix-like systems, it is an error if this file is readable by group or others.
+//
+// The SECRET_ prefix keeps secrets apart from the other environment variables of the
+// application, such as $APP_NAME_CONFIG_DIR and the configuration keys of LoadConfig.
+//
+// Trailing newlines are trimmed. The name must be in [a-zA-Z0-9-_].
+// Files are read from the FileSystem of the container.
+// Returns an error that wraps fs.ErrNotExist if the secret is not set.
+func ReadSecret(container NameContainer, name string) (string, error) {
+	return readSecret(container, name)
+}
+
+// WriteSecret writes the secret with the name to the secrets directory in ConfigDirPath.
+//
+// The directory is created with permissions 0700 if it does not exist, and the file is
+// written atomically with permissions 0600. The name must be in [a-zA-Z0-9-_].
+// The file is written to the FileSystem of the container.
+func WriteSecret(container NameContainer, name string, secret string) error {
+	secretFilePath, err := getSecretFilePath(container, name)
+	if err != nil {
+		return err
+	}
+	if err := container.FileSystem().MkdirAll(filepath.Dir(secretFilePath), 0700); err != nil {
+		return err
+	}
+	return writeFileAtomic(container.FileSystem(), secretFilePath, []byte(secret), 0600)
+}
+
+// DeleteSecret deletes the secret with the name from the secrets directory in ConfigDirPath.
+//
+// Returns an error that wraps fs.ErrNotExist if the secret does not exist.
+// The file is removed from the FileSystem of the container.
+func DeleteSecret(container NameContainer, name string) error {
+	secretFilePath, err := getSecretFilePath(container, name)
+	if err != nil {
+		return err
+	}
+	return container.FileSystem().Remove(secretFilePath)
+}
+
+// ListSecrets returns the sorted names of the secrets in the secrets directory in ConfigDirPath.
+//
+// Secrets set by environment variables are not included.
+// If the directory does not exist, returns empty.
+func ListSecrets(container NameContainer) ([]string, error) {
+	return listSecrets(container)
+}
+
+// SecretProvider stores and retrieves named secrets.
+type SecretProvider interface {
+	// GetSecret returns the secret with the name.
+	//
+	// Returns an error that wraps fs.ErrNotExist if the secret does not exist.
+	GetSecret(ctx context.Context, name string) (string, error)
+	// StoreSecret stores the secret with the name, replacing any existing secret.
+	StoreSecret(ctx context.Context, name string, secret string) error
+	// EraseSecret erases the secret with the name.
+	//
+	// Succeeds if the secret does not exist, as secret helpers cannot report it.
+	EraseSecret(ctx context.Context, name string) error
+}
+
+// NewSecretProvider returns a new SecretProvider for the named application.
+//
+// If $APP_NAME_SECRETS_HELPER is set, this is NewHelperSecretProvider for the helper.
+// Otherwise, this is NewFileSecretProvider.
+func NewSecretProvider(container NameContainer) SecretProvider {
+	if helper := container.Env(getAppNameEnvPrefix(container.AppName()) + secretHelperEnvSuffix); helper != "" {
+		return NewHelperSecretProvider(container, helper)
+	}
+	return NewFileSecretProvider(container)
+}
+
+// NewFileSecretProvider returns a new SecretProvider that uses ReadSecret, WriteSecret,
+// and DeleteSecret.
+//
+// EraseSecret succeeds if the secret does not exist, unlike DeleteSecret.
+func NewFileSecretProvider(container NameContainer) SecretProvider {
+	return newFileSecretProvider(container)
+}
+
+// NewHelperSecretProvider returns a new SecretProvider that invokes an external helper
+// program, such as one that integrates with the keychain of the operating system.
+//
+// The helper is a program name or path, optionally followed by arguments separated by
+// whitespace. It is run with app.Exec with one additional argument, the operation, which
+// is one of get, store, or erase. The helper reads lines of the form key=value from stdin
+// until EOF:
+//
+//   - name: the name of the secret, for all operations.
+//   - secret: the secret, for store.
+//
+// For get, the helper writes the line secret=value to stdout, or writes nothing if the
+// secret does not exist. For erase, the helper should succeed if the secret does not
+// exist. Unknown keys should be ignored by both the helper and this provider.
+//
+// Secrets may not contain newlines. The stderr of the helper is included in the
+// returned error if the helper fails.
+func NewHelperSecretProvider(container app.Container, helper string) SecretProvider {
+	return newHelperSecretProvider(container, helper)
+}
+
+// WriteConfig writes the configuration to the configuration file in the configuration directory.
+//
+// If a configuration file already exists in ConfigDirPath, it is written with the ConfigCodec
+// for its file extension. Otherwise, the first ConfigCodec is used, which is YAML by default,
+// that is config.yaml is written.
+//
+// The file is written atomically by writing a temporary file and renaming it, while holding
+// the advisory lock on the configuration directory, so that concurrent writers and crashes
+// never leave a partially-written file. If the file is a symbolic link, the target of the
+// link is written. See UpdateConfig for read-modify-write cycles.
+//
+// If the existing file has a profiles section, it is preserved. See LoadConfig.
+//
+// The file is written to the FileSystem of the container.
+// The directory is created if it does not exist.
+// The value should be a pointer to marshal.
+func WriteConfig(container NameContainer, value any, options ...ConfigOption) error {
+	return WriteConfigContext(context.Background(), container, value, options...)
+}
+
+// WriteConfigContext is WriteConfig, except that waiting for the advisory lock on the
+// configuration directory also ends when the context is done.
+func WriteConfigContext(ctx context.Context, container NameContainer, value any, options ...ConfigOption) error {
+	configOptions := newConfigOptions(container.AppName())
+	for _, option := range options {
+		option(configOptions)
+	}
+	return withConfigLock(
+		ctx,
+		container,
+		configOptions,
+		func() error {
+			return writeConfig(container, value, configOptions)
+		},
+	)
+}
+
+// UpdateConfig reads the configuration from the configuration file in ConfigDirPath,
+// calls update, and writes the configuration back, while holding the advisory lock on the
+// configuration directory.
+//
+// Only the configuration file in ConfigDirPath is read, so that system-wide configuration
+// is not copied into it. If the file does not exist, update is called with the zero value.
+// If update returns an error, nothing is written and the error is returned.
+//
+// Only the base section of the file is read and updated, and the profiles section is
+// preserved. Environment variables are not interpolated, so that references are not
+// replaced by their values.
+//
+// The file is read and written in the same manner as ReadConfig and WriteConfig.
+func UpdateConfig[T any](container NameContainer, update func(*T) error, options ...ConfigOption) error {
+	return UpdateConfigContext(context.Background(), container, update, options...)
+}
+
+// UpdateConfigContext is UpdateConfig, except that waiting for the advisory lock on the
+// configuration directory also ends when the context is done.
+func UpdateConfigContext[T any](ctx context.Context, container NameContainer, update func(*T) error, options ...ConfigOption) error {
+	configOptions := newConfigOptions(container.AppName())
+	for _, option := range options {
+		option(configOptions)
+	}
+	return withConfigLock(
+		ctx,
+		container,
+		configOptions,
+		func() error {
+			value := new(T)
+			// Only the base section is updated, and the file is written back as is otherwise.
+			baseConfigOptions := *configOptions
+			baseConfigOptions.profile = new(string)
+			baseConfigOptions.interpolateEnv = false
+			// The migrated configuration is written below, so it is not written back here.
+			if err := readConfigFromDirPaths(container, []string{container.ConfigDirPath()}, value, true, false, &baseConfigOptions); err != nil {
+				return err
+			}
+			if err := update(value); err != nil {
+				return err
+			}
+			return writeConfig(container, value, configOptions)
+		},
+	)
+}
+
+// NewConfigCommand returns a new appcmd.Command to manage the configuration file for
+// configuration of type T.
+//
+// The command has the following sub-commands, which address values by dotted keys derived
+// from the yaml struct tags of T, as in LoadConfig:
+//
+//   - get KEY: print the value of the key, as read by ReadConfig.
+//   - list: print all keys and values, as read by ReadConfig.
+//   - set KEY VALUE: set the key in the configuration file in ConfigDirPath. The value is
+//     kept as a string if the field of T is a string, and otherwise parsed as a YAML scalar
+//     or flow sequence, and checked against T.
+//   - unset KEY: remove the key from the configuration file in ConfigDirPath.
+//   - path: print the path of the configuration file in ConfigDirPath.
+//   - edit: open the configuration file in ConfigDirPath with $VISUAL or $EDITOR, and
+//     validate it afterwards.
+//
+// If a profile is selected, as with ReadConfig, get and list print the values with the
+// profile applied, and set and unset change the key within the profile.
+//
+// Set and unset rewrite the configuration file with its ConfigCodec, so comments and
+// formatting in the file are not kept. Use edit to keep them.
+//
+// The options are passed to ReadConfig and WriteConfig.
+func NewConfigCommand[T any](use string, builder SubCommandBuilder, options ...ConfigOption) *appcmd.Command {
+	configCommand := &configCommand[T]{
+		options: options,
+	}
+	return &appcmd.Command{
+		Use:   use,
+		Short: "Manage the configuration",
+		SubCommands: []*appcmd.Command{
+			{
+				Use:   "get <key>",
+				Short: "Print the value of a configuration key",
+				Args:  appcmd.ExactArgs(1),
+				Run:   builder.NewRunFunc(configCommand.get),
+			},
+			{
+				Use:   "list",
+				Short: "Print all configuration keys and values",
+				Args:  appcmd.NoArgs,
+				Run:   builder.NewRunFunc(configCommand.list),
+			},
+			{
+				Use:   "set <key> <value>",
+				Short: "Set the value of a configuration key",
+				Long:  "The configuration file is rewritten, so comments and formatting in it are not kept. Use edit to keep them.",
+				Args:  appcmd.ExactArgs(2),
+				Run:   builder.NewRunFunc(configCommand.set),
+			},
+			{
+				Use:   "unset <key>",
+				Short: "Remove a configuration key",
+				Long:  "The configuration file is rewritten, so comments and formatting in it are not kept. Use edit to keep them.",
+				Args:  appcmd.ExactArgs(1),
+				Run:   builder.NewRunFunc(configCommand.unset),
+			},
+			{
+				Use:   "path",
+				Short: "Print the path of the configuration file",
+				Args:  appcmd.NoArgs,
+				Run:   builder.NewRunFunc(configCommand.path),
+			},
+			{
+				Use:   "edit",
+				Short: "Edit the configuration file with $VISUAL or $EDITOR",
+				Args:  appcmd.NoArgs,
+				Run:   builder.NewRunFunc(configCommand.edit),
+			},
+		},
+	}
+}
+
+// ConfigWatcher watches the configuration for changes, for long-running servers.
+type ConfigWatcher[T any] interface {
+	// Config returns the last good configuration.
+	//
+	// The returned value must not be modified.
+	Config() *T
+	// Updates returns the channel on which new configurations are delivered.
+	//
+	// The channel has a buffer of one, and only the latest configuration is kept if the
+	// receiver falls behind. The channel is closed when the ConfigWatcher is closed.
+	Updates() <-chan *T
+	// Reload re-reads the configuration immediately, regardless of whether any
+	// configuration file changed.
+	//
+	// Returns the error if the configuration could not be read or is not valid, in which
+	// case the last good configuration is kept.
+	Reload() error
+	// Close stops watching and closes the Updates channel.
+	Close() error
+}
+
+// NewConfigWatcher returns a new ConfigWatcher for configuration of type T.
+//
+// The configuration is read with LoadConfig into a new T. The configuration files are
+// polled at the interval, and the configuration is re-read when the content of any
+// configuration file changes. On unix-like systems, the configuration is also re-read
+// when the process receives SIGHUP. Polling is done with the Clock of the container.
+//
+// When the configuration is re-read, it is validated with the function given by
+// ConfigWatcherWithValidate, if any. If it is valid and differs from the last good
+// configuration, it becomes the last good configuration and is delivered on the Updates
+// channel and to the function given by ConfigWatcherWithCallback, if any. If it is not
+// valid, the last good configuration is kept and the error is passed to the function
+// given by ConfigWatcherWithErrorHandler, if any.
+//
+// Returns error if the initial configuration cannot be read or is not valid. Watching
+// stops when the context is canceled or the ConfigWatcher is closed.
+func NewConfigWatcher[T any](ctx context.Context, container NameContainer, options ...ConfigWatcherOption[T]) (ConfigWatcher[T], error) {
+	return newConfigWatcher(ctx, container, options...)
+}
+
+// ConfigWatcherOption is an option for a new ConfigWatcher.
+type ConfigWatcherOption[T any] func(*configWatcherOptions[T])
+
+// ConfigWatcherWithInterval returns a new ConfigWatcherOption that sets the polling interval.
+//
+// The default is 2 seconds.
+func ConfigWatcherWithInterval[T any](interval time.Duration) ConfigWatcherOption[T] {
+	return func(configWatcherOptions *configWatcherOptions[T]) {
+		configWatcherOptions.interval = interval
+	}
+}
+
+// ConfigWatcherWithValidate returns a new ConfigWatcherOption that validates each
+// configuration that is read.
+func ConfigWatcherWithValidate[T any](validate func(*T) error) ConfigWatcherOption[T] {
+	return func(configWatcherOptions *configWatcherOptions[T]) {
+		configWatcherOptions.validate = validate
+	}
+}
+
+// ConfigWatcherWithCallback returns a new ConfigWatcherOption that calls the function
+// with each new configuration, in addition to delivering it on the Updates channel.
+//
+// The function is called from the goroutine of the ConfigWatcher, or from the caller of Reload,
+// without holding any locks of the ConfigWatcher, so it may call Reload. It may be called
+// concurrently if Reload is called concurrently. It must not call Close, as Close waits for
+// the goroutine of the ConfigWatcher to return.
+func ConfigWatcherWithCallback[T any](callback func(*T)) ConfigWatcherOption[T] {
+	return func(configWatcherOptions *configWatcherOptions[T]) {
+		configWatcherOptions.callback = callback
+	}
+}
+
+// ConfigWatcherWithErrorHandler returns a new ConfigWatcherOption that calls the function
+// with each error encountered while watching, such as an invalid configuration.
+//
+// Errors from Reload are returned to the caller of Reload instead.
+func ConfigWatcherWithErrorHandler[T any](errorHandler func(error)) ConfigWatcherOption[T] {
+	return func(configWatcherOptions *configWatcherOptions[T]) {
+		configWatcherOptions.errorHandler = errorHandler
+	}
+}
+
+// ConfigWatcherWithConfigOptions returns a new ConfigWatcherOption that passes the
+// ConfigOptions to LoadConfig.
+func ConfigWatcherWithConfigOptions[T any](options ...ConfigOption) ConfigWatcherOption[T] {
+	return func(configWatcherOptions *configWatcherOptions[T]) {
+		configWatcherOptions.configOptions = append(configWatcherOptions.configOptions, options...)
+	}
+}
+
+// Cache is a cache of keyed entries in a namespace of CacheDirPath.
+//
+// Entries are stored as files named by the SHA-256 hash of the key, and are written
+// atomically, so that concurrent readers never see partially-written entries. The time
+// an entry was written is stored at the start of its file, and the modification time of
+// the file is the time the entry was last used. Changes
+// to the set of entries are made while holding an advisory lock on the cache directory,
+// so that the cache can be shared by concurrent processes.
+type Cache interface {
+	// DirPath returns the directory of the cache.
+	DirPath() string
+	// Get returns the data of the entry for the key.
+	//
+	// The entry is marked as recently used. Returns an error that wraps fs.ErrNotExist
+	// if there is no entry for the key, or if the entry was written longer ago than the
+	// maximum age.
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Put sets the data of the entry for the key, and then evicts entries as with Evict.
+	Put(ctx context.Context, key string, data []byte) error
+	// Delete deletes the entry for the key.
+	//
+	// This is a no-op if there is no entry for the key.
+	Delete(ctx context.Context, key string) error
+	// Evict deletes all entries written longer ago than the maximum age, and then deletes
+	// the least recently used entries until the total size is within the maximum size.
+	Evict(ctx context.Context) error
+	// Clear deletes all entries.
+	Clear(ctx context.Context) error
+	// Info returns information about the cache.
+	Info(ctx context.Context) (CacheInfo, error)
+}
+
+// CacheInfo is information about a Cache.
+type CacheInfo struct {
+	// Namespace is the namespace of the cache.
+	Namespace string
+	// DirPath is the directory of the cache.
+	DirPath string
+	// Entries is the number of entries.
+	Entries int
+	// Bytes is the total size of the data of all entries.
+	Bytes int64
+}
+
+// NewCache returns a new Cache for the namespace within CacheDirPath.
+//
+// The namespace must be in [a-zA-Z0-9-_]. The directory is created if it does not exist,
+// and is marked with a CACHEDIR.TAG file, so that backup tools skip it and NewCacheCommand
+// finds it. Times are measured with the Clock of the container, and files are read and
+// written with the FileSystem of the container.
+func NewCache(container NameContainer, namespace string, options ...CacheOption) (Cache, error) {
+	return newCache(container, namespace, options...)
+}
+
+// CacheOption is an option for a new Cache.
+type CacheOption func(*cacheOptions)
+
+// CacheWithMaxBytes returns a new CacheOption that sets the maximum total size of all entries.
+//
+// The default is 0, which means no maximum.
+func CacheWithMaxBytes(maxBytes int64) CacheOption {
+	return func(cacheOptions *cacheOptions) {
+		cacheOptions.maxBytes = maxBytes
+	}
+}
+
+// CacheWithMaxAge returns a new CacheOption that sets the maximum age of entries, measured
+// from when the entry was written, regardless of when it was last used.
+//
+// The default is 0, which means no maximum.
+func CacheWithMaxAge(maxAge time.Duration) CacheOption {
+	return func(cacheOptions *cacheOptions) {
+		cacheOptions.maxAge = maxAge
+	}
+}
+
+// CacheWithLockTimeout returns a new CacheOption that sets how long to wait for the
+// advisory lock on the cache directory.
+//
+// The default is 10 seconds.
+func CacheWithLockTimeout(lockTimeout time.Duration) CacheOption {
+	return func(cacheOptions *cacheOptions) {
+		cacheOptions.lockTimeout = lockTimeout
+	}
+}
+
+// NewCacheCommand returns a new appcmd.Command to manage the caches created with NewCache.
+//
+// The command has the following sub-commands:
+//
+//   - clean [NAMESPACE...]: delete all entries of the caches with the namespaces, or of all caches.
+//   - info: print the namespace, number of entries, total size, and directory of all caches.
+func NewCacheCommand(use string, builder SubCommandBuilder) *appcmd.Command {
+	return &appcmd.Command{
+		Use:   use,
+		Short: "Manage the cache",
+		SubCommands: []*appcmd.Command{
+			{
+				Use:   "clean [namespace...]",
+				Short: "Delete all cache entries",
+				Args:  appcmd.ArbitraryArgs,
+				Run:   builder.NewRunFunc(runCacheClean),
+			},
+			{
+				Use:   "info",
+				Short: "Print information about the cache",
+				Args:  appcmd.NoArgs,
+				Run:   builder.NewRunFunc(runCacheInfo),
+			},
+		},
+	}
+}
+
+// Unlocker releases a lock.
+type Unlocker interface {
+	// Unlock releases the lock.
+	//
+	// Subsequent calls return the result of the first call.
+	Unlock() error
+}
+
+// LockCacheDir acquires the advisory lock with the name in CacheDirPath.
+//
+// This is used to serialize access to the cache directory between processes, such as
+// concurrent invocations of the same command. The lock is held with FileSystem.TryLock on
+// a file named by the name with the suffix .lock, so that the operating system releases
+// the lock if the holder exits without releasing it. The lock file is not removed. While
+// an exclusive lock is held, the lock file contains the PID and hostname of the holder,
+// which are only used to print the holder, and not to detect stale locks.
+//
+// With NewFileSystemForOS on platforms without locks of the operating system, such as aix,
+// plan9, js/wasm, and wasip1, and with in-memory file systems, the lock only excludes holders
+// within the same process, and does not serialize access between processes.
+//
+// By default, the lock is exclusive, and waits until the context is done. If the lock is
+// held by another holder, a message is printed to the stderr of the container while
+// waiting, with the holder if it is known. Waiting is measured with the Clock of the
+// container, and the lock is attempted at an interval, so waiters are not ordered.
+//
+// The name must be in [a-zA-Z0-9-_]. The directory is created if it does not exist.
+func LockCacheDir(ctx context.Context, container NameContainer, name string, options ...LockOption) (Unlocker, error) {
+	return lockDir(ctx, container, container.CacheDirPath(), name, options...)
+}
+
+// LockDataDir acquires the advisory lock with the name in DataDirPath.
+//
+// The lock is acquired in the same manner as LockCacheDir.
+func LockDataDir(ctx context.Context, container NameContainer, name string, options ...LockOption) (Unlocker, error) {
+	return lockDir(ctx, container, container.DataDirPath(), name, options...)
+}
+
+// LockOption is an option for LockCacheDir and LockDataDir.
+type LockOption func(*lockOptions)
+
+// LockWithShared returns a new LockOption that acquires a shared lock instead of an
+// exclusive lock.
+//
+// Any number of holders can hold a shared lock at the same time, while an exclusive
+// lock excludes all other holders. New shared locks are acquired while an exclusive lock
+// is being waited for, so an exclusive lock can wait as long as shared locks overlap.
+func LockWithShared() LockOption {
+	return func(lockOptions *lockOptions) {
+		lockOptions.shared = true
+	}
+}
+
+// LockWithTimeout returns a new LockOption that sets how long to wait for the lock.
+//
+// If timeout is 0, the lock is only attempted once. The default is to wait until the
+// context is done.
+func LockWithTimeout(timeout time.Duration) LockOption {
+	return func(lockOptions *lockOptions) {
+		lockOptions.timeout = timeout
+	}
+}
+
+// InstanceStatus is the status of the instance of an application, as recorded in its PID file.
+//
+// See BuilderWithPIDFile.
+type InstanceStatus struct {
+	// PIDFilePath is the path of the PID file.
+	PIDFilePath string
+	// PID is the PID in the PID file, or 0 if there is no PID file.
+	PID int
+	// Running is true if the PID file is locked by a running instance.
+	//
+	// The PID alone is not used, as it may have been reused by another process.
+	Running bool
+}
+
+// GetInstanceStatus returns the status of the instance of the application from the PID file
+// written with BuilderWithPIDFile.
+//
+// Whether the instance is running is checked by briefly acquiring a shared lock on the PID
+// file. The PID file is not removed, even if the instance is not running.
+func GetInstanceStatus(container NameContainer) (InstanceStatus, error) {
+	return getInstanceStatus(container)
+}
+
+// StopInstance stops the running instance of the application from the PID file written with
+// BuilderWithPIDFile, and waits until it exits or the context is done.
+//
+// The instance is only signaled if the PID file is locked, and StopInstance waits until the
+// lock is released.
+//
+// On unix-like platforms, the instance is sent SIGTERM, which interrupts the context of the
+// command, so that the instance exits gracefully. On windows, the instance is killed.
+// Returns an error if the instance is not running.
+func StopInstance(ctx context.Context, container NameContainer) error {
+	return stopInstance(ctx, container)
+}
+
+// NewInstanceCommand returns a new appcmd.Command to manage the instance of the application
+// that writes a PID file with BuilderWithPIDFile.
+//
+// The command has the following sub-commands:
+//
+//   - status: print whether the instance is running, and its PID.
+//   - stop: stop the running instance as with StopInstance.
+func NewInstanceCommand(use string, builder SubCommandBuilder) *appcmd.Command {
+	return &appcmd.Command{
+		Use:   use,
+		Short: "Manage the running instance",
+		SubCommands: []*appcmd.Command{
+			{
+				Use:   "status",
+				Short: "Print whether an instance is running",
+				Args:  appcmd.NoArgs,
+				Run:   builder.NewRunFunc(runInstanceStatus),
+			},
+			{
+				Use:   "stop",
+				Short: "Stop the running instance",
+				Args:  appcmd.NoArgs,
+				Run:   builder.NewRunFunc(runInstanceStop),
+			},
+		},
+	}
+}
+
+// ConfigCodec marshals and unmarshals configuration files of a given format.
+//
+// Configuration is represented as a generic tree of map[string]any, []any, and scalar
+// values such as string, bool, int64, float64, and time.Time.
+type ConfigCodec interface {
+	// FileExtension is the file extension for the format, including the leading ".".
+	FileExtension() string
+	// Marshal marshals the tree.
+	Marshal(tree map[string]any) ([]byte, error)
+	// Unmarshal unmarshals the data into a tree.
+	Unmarshal(data []byte) (map[string]any, error)
+}
+
+// NewYAMLConfigCodec returns a new ConfigCodec for YAML with the file extension .yaml.
+func NewYAMLConfigCodec() ConfigCodec {
+	return yamlConfigCodec{}
+}
+
+// NewJSONConfigCodec returns a new ConfigCodec for JSON with the file extension .json.
+func NewJSONConfigCodec() ConfigCodec {
+	return jsonConfigCodec{}
+}
+
+// NewTOMLConfigCodec returns a new ConfigCodec for TOML with the file extension .toml.
+//
+// Files are decoded with github.com/BurntSushi/toml. Offset date-times are decoded as
+// time.Time, and local date-times, dates, and times are decoded as strings. TOML has no
+// null value, so null values are omitted when marshaling.
+func NewTOMLConfigCodec() ConfigCodec {
+	return tomlConfigCodec{}
+}
+
+// ConfigSource is the source of a configuration value.
+type ConfigSource struct {
+	// Layer is the layer the value came from.
+	Layer ConfigLayer
+	// Path is the file path for file layers, the environment variable name for
+	// ConfigLayerEnv, and the flag name for ConfigLayerFlag.
+	//
+	// Empty for ConfigLayerDefault.
+	Path string
+}
+
+// ConfigSources maps the dotted keys of configuration values to their sources.
+//
+// For example, the key for the field tagged `yaml:"addr"` within the field tagged
+// `yaml:"server"` is server.addr.
+type ConfigSources map[string]ConfigSource
+
+// LoadConfig loads the configuration from all layers into the value, returning the
+// source of each final configuration value.
+//
+// The value should be a pointer to unmarshal into. The current contents of the value are
+// used as the defaults. The layers are merged in the following order, with later layers
+// taking precedence:
+//
+//   - ConfigLayerDefault: the current contents of the value.
+//   - ConfigLayerSystem: the configuration files in the system-wide directories of ConfigDirPaths.
+//   - ConfigLayerUser: the configuration file in ConfigDirPath.
+//   - ConfigLayerProject: the project configuration file, found by walking up from the
+//     working directory. See ConfigWithProjectFileName. The ConfigCodec is chosen by the file extension.
+//   - ConfigLayerEnv: environment variables named by the APP_NAME_ prefix followed by the key
+//     in upper case, with "." and "-" replaced by "_". For example, server.addr is set by
+//     APP_NAME_SERVER_ADDR.
+//   - ConfigLayerFlag: flags that were explicitly set, if ConfigWithFlagSet is used. Flags are
+//     named by the key with "." and "_" replaced by "-". For example, server.addr is set by
+//     --server-addr.
+//
+// Maps are merged, and all other values are replaced. Environment variable and flag values
+// for string fields of the value are used as is, and values for other fields are parsed as
+// YAML scalars, so that for example 0755 is kept as a string for a string field, and is
+// parsed as a number for an int field. The keys that can be set by environment variables
+// and flags are the keys of the non-map fields of the type of the value, including fields
+// within nil pointers, and the keys of map entries present in the defaults. Files are read from the FileSystem of the container, and missing
+// files are skipped. Unknown keys in files are an error.
+//
+// Configuration files may contain a profiles section, which maps profile names to
+// configuration that is merged over the rest of the file if the profile is selected.
+// For example:
+//
+//	server:
+//	  addr: localhost:8080
+//	profiles:
+//	  production:
+//	    server:
+//	      addr: example.com:443
+//
+// The profile is NameContainer.Profile, or the profile given with ConfigWithProfile. The
+// profile is applied within each file before the file is merged, and it is an error if
+// the profile is selected but no file contains it.
+//
+// Configuration files are found in the same manner as ReadConfig.
+func LoadConfig(container NameContainer, value any, options ...ConfigOption) (ConfigSources, error) {
+	configOptions := newConfigOptions(container.AppName())
+	for _, option := range options {
+		option(configOptions)
+	}
+	return loadConfig(container, value, configOptions)
+}
+
+// ConfigOption is an option for LoadConfig, ReadConfig, ReadConfigNonStrict, WriteConfig,
+// UpdateConfig, WriteConfigContext, and UpdateConfigContext.
+type ConfigOption func(*configOptions)
+
+// ConfigWithFlagSet returns a new ConfigOption that uses the explicitly-set flags of the
+// FlagSet as the ConfigLayerFlag layer.
+func ConfigWithFlagSet(flagSet *pflag.FlagSet) ConfigOption {
+	return func(configOptions *configOptions) {
+		configOptions.flagSet = flagSet
+	}
+}
+
+// ConfigWithCodecs returns a new ConfigOption that sets the ConfigCodecs used to find,
+// read, and write configuration files.
+//
+// The first ConfigCodec is used to write new configuration files.
+// The default is NewYAMLConfigCodec, NewJSONConfigCodec, and NewTOMLConfigCodec.
+func ConfigWithCodecs(codecs ...ConfigCodec) ConfigOption {
+	return func(configOptions *configOptions) {
+		if len(codecs) > 0 {
+			configOptions.codecs = codecs
 		}
-		if err := unmarshalYAMLStrict(data, value); err != nil {
-			return fmt.Errorf("invalid %s configuration file: %w", container.AppName(), err)
+	}
+}
+
+// ConfigWithLockTimeout returns a new ConfigOption that sets how long WriteConfig and
+// UpdateConfig wait for the advisory lock on the configuration directory.
+//
+// The default is 10 seconds.
+func ConfigWithLockTimeout(lockTimeout time.Duration) ConfigOption {
+	return func(configOptions *configOptions) {
+		configOptions.lockTimeout = lockTimeout
+	}
+}
+
+// ConfigWithMigrations returns a new ConfigOption that migrates configuration files to the
+// current version when they are read.
+//
+// The version of a configuration file is the integer value of its top-level version key,
+// or 0 if the key is not present. The migration for key N migrates the generic tree of a
+// configuration file from version N to version N+1, and migrations are applied in order
+// until the current version is reached, after which the version key is set to currentVersion.
+// The value read into should have a version field, for example `yaml:"version"`, so that
+// strict reads accept the version key.
+//
+// It is an error if a configuration file has a version greater than currentVersion, or if a
+// required migration is missing. WriteConfig and UpdateConfig set the version key to
+// currentVersion, so that the files they write are not migrated.
+func ConfigWithMigrations(currentVersion int, migrations map[int]func(map[string]any) error) ConfigOption {
+	return func(configOptions *configOptions) {
+		configOptions.migrations = &configMigrations{
+			currentVersion: currentVersion,
+			migrations:     migrations,
 		}
 	}
-	return nil
 }
 
-// ReadConfigNonStrict reads the configuration from the YAML configuration file config.yaml
-// in the configuration directory, ignoring any unknown properties.
+// ConfigWithWriteMigrated returns a new ConfigOption that writes migrated configuration files
+// back when they are read, if ConfigWithMigrations is used.
 //
-// If the file does not exist, this is a no-op.
-// The value should be a pointer to unmarshal into.
-func ReadConfigNonStrict(container NameContainer, value any) error {
-	configFilePath := filepath.Join(container.ConfigDirPath(), configFileName)
-	data, err := os.ReadFile(configFilePath)
-	if !errors.Is(err, os.ErrNotExist) {
+// Only the configuration file in ConfigDirPath is written back, as system-wide and project
+// configuration files are not owned by the application. The file is written in the same
+// manner as WriteConfig, except that it is not written back if the advisory lock on the
+// configuration directory is held, in which case it is migrated again when it is next read.
+func ConfigWithWriteMigrated() ConfigOption {
+	return func(configOptions *configOptions) {
+		configOptions.writeMigrated = true
+	}
+}
+
+// ConfigWithEnvInterpolation returns a new ConfigOption that expands environment variable
+// references in string values of configuration files before they are decoded.
+//
+// References have the form ${VAR} or ${VAR:-default}, where the default is used if VAR is
+// not set or empty. Use $$ for a literal $. Variables are looked up in the EnvContainer.
+// If any variable without a default is not set, an error listing all such variables is
+// returned.
+//
+// Expanded values are kept as strings, unless they are decoded into a field that is not a
+// string and were not quoted, in which case they are parsed as YAML scalars, so that for
+// example port: ${PORT} can be decoded into an int.
+//
+// Values set with WriteConfig are not escaped, so this should only be used for
+// configuration files that are written by hand.
+func ConfigWithEnvInterpolation() ConfigOption {
+	return func(configOptions *configOptions) {
+		configOptions.interpolateEnv = true
+	}
+}
+
+// ConfigWithProfile returns a new ConfigOption that selects the configuration profile,
+// overriding NameContainer.Profile.
+//
+// If profile is empty, no profile is used. See LoadConfig for how profiles are applied.
+func ConfigWithProfile(profile string) ConfigOption {
+	return func(configOptions *configOptions) {
+		configOptions.profile = &profile
+	}
+}
+
+// ConfigWithProjectFileName returns a new ConfigOption that sets the file name of the
+// project configuration file.
+//
+// The default is .app-name.yaml.
+func ConfigWithProjectFileName(projectFileName string) ConfigOption {
+	return func(configOptions *configOptions) {
+		configOptions.projectFileName = projectFileName
+	}
+}
+
+// Listen listens on the container's port, falling back to defaultPort.
+//
+// If the command was built with BuilderWithPIDFile, the PID file is written first.
+func Listen(ctx context.Context, container NameContainer, defaultPort uint16) (net.Listener, error) {
+	port, err := container.Port()
+	if err != nil {
+		return nil, err
+	}
+	if pidFile := getPIDFileForContext(ctx); pidFile != nil {
+		if err := pidFile.create(); err != nil {
+			return nil, err
+		}
+	}
+	if port == 0 {
+		port = defaultPort
+	}
+	// Must be 0.0.0.0
+	var listenConfig net.ListenConfig
+	return listenConfig.Listen(ctx, "tcp", fmt.Sprintf("0.0.0.0:%d", port))
+}
+
+// *** PRIVATE ***
+
+func readConfig(container NameContainer, value any, strict bool, options ...ConfigOption) error {
+	configOptions := newConfigOptions(container.AppName())
+	for _, option := range options {
+		option(configOptions)
+	}
+	return readConfigFromDirPaths(container, container.ConfigDirPaths(), value, strict, configOptions.writeMigrated, configOptions)
+}
+
+// readConfigFromDirPaths reads the first configuration file found in the directories.
+func readConfigFromDirPaths(
+	container NameContainer,
+	configDirPaths []string,
+	value any,
+	strict bool,
+	writeMigrated bool,
+	configOptions *configOptions,
+) error {
+	for _, configDirPath := range configDirPaths {
+		configFilePath, codec, err := findConfigFile(container, configDirPath, configFileBaseName, configOptions.codecs)
+		if err != nil {
+			return err
+		}
+		if codec == nil {
+			continue
+		}
+		data, err := container.FileSystem().ReadFile(configFilePath)
 		if err != nil {
 			return fmt.Errorf("could not read %s configuration file at %s: %w", container.AppName(), configFilePath, err)
 		}
-		if err := unmarshalYAMLNonStrict(data, value); err != nil {
+		if len(data) == 0 {
+			return nil
+		}
+		tree, err := codec.Unmarshal(data)
+		if err != nil {
+			return fmt.Errorf("invalid %s configuration file: %w", container.AppName(), err)
+		}
+		profile := getConfigProfile(container, configOptions)
+		if _, hasProfiles := tree[configProfilesKey]; configOptions.migrations == nil && !configOptions.interpolateEnv && profile == "" && !hasProfiles {
+			// Unmarshal the data directly, so that errors refer to positions in the file.
+			if err := unmarshalConfig(codec, data, value, strict); err != nil {
+				return fmt.Errorf("invalid %s configuration file: %w", container.AppName(), err)
+			}
+			return nil
+		}
+		var migrated bool
+		if configOptions.migrations != nil {
+			migrated, err = configOptions.migrations.migrate(container.AppName(), configFilePath, tree)
+			if err != nil {
+				return err
+			}
+		}
+		// Resolve a copy, so that the profile and expanded values are never written back.
+		valueTree := normalizeConfigTree(tree)
+		found, err := applyConfigProfile(valueTree, profile)
+		if err != nil {
+			return fmt.Errorf("invalid %s configuration file at %s: %w", container.AppName(), configFilePath, err)
+		}
+		if profile != "" && !found {
+			return fmt.Errorf("profile %q not found in %s configuration file at %s", profile, container.AppName(), configFilePath)
+		}
+		if configOptions.interpolateEnv {
+			if err := interpolateConfigTree(container, valueTree, reflect.TypeOf(value), getConfigQuotedStrings(codec, data)); err != nil {
+				return fmt.Errorf("invalid %s configuration file at %s: %w", container.AppName(), configFilePath, err)
+			}
+		}
+		if err := unmarshalConfigTree(valueTree, value, strict); err != nil {
 			return fmt.Errorf("invalid %s configuration file: %w", container.AppName(), err)
 		}
+		if migrated && writeMigrated && configDirPath == container.ConfigDirPath() {
+			return writeMigratedConfig(container, configFilePath, codec, configOptions)
+		}
+		return nil
 	}
 	return nil
 }
 
-// ReadSecret returns the contents of the file at path
-// filepath.Join(container.ConfigDirPath(), secretRelDirPath, name).
-func ReadSecret(container NameContainer, name string) (string, error) {
-	secretFilePath := filepath.Join(container.ConfigDirPath(), secretRelDirPath, name)
-	data, err := os.ReadFile(secretFilePath)
-	if err != nil {
-		return "", fmt.Errorf("failed to read secret at %s: %w", secretFilePath, err)
+// writeMigratedConfig migrates the configuration file and writes it back.
+//
+// The file is read and migrated again while holding the configuration lock, so that
+// changes written since it was read are not lost. The file is not written back if the
+// lock is held, as the file is migrated again when it is next read.
+func writeMigratedConfig(
+	container NameContainer,
+	configFilePath string,
+	codec ConfigCodec,
+	configOptions *configOptions,
+) error {
+	// Reads have no context, so the lock is only attempted once.
+	lockConfigOptions := *configOptions
+	lockConfigOptions.lockTimeout = 0
+	err := withConfigLock(
+		context.Background(),
+		container,
+		&lockConfigOptions,
+		func() error {
+			data, err := container.FileSystem().ReadFile(configFilePath)
+			if err != nil {
+				if errors.Is(err, os.ErrNotExist) {
+					return nil
+				}
+				return err
+			}
+			tree, err := codec.Unmarshal(data)
+			if err != nil {
+				return fmt.Errorf("invalid %s configuration file at %s: %w", container.AppName(), configFilePath, err)
+			}
+			migrated, err := configOptions.migrations.migrate(container.AppName(), configFilePath, tree)
+			if err != nil || !migrated {
+				return err
+			}
+			data, err = codec.Marshal(tree)
+			if err != nil {
+				return err
+			}
+			return writeConfigFile(container, configFilePath, data)
+		},
+	)
+	if errors.Is(err, app.ErrLocked) {
+		return nil
 	}
-	return string(data), nil
+	return err
 }
 
-// WriteConfig writes the configuration to the YAML configuration file config.yaml
-// in the configuration directory.
+// writeConfig writes the configuration file atomically.
 //
-// The directory is created if it does not exist.
-// The value should be a pointer to marshal.
-func WriteConfig(container NameContainer, value any) error {
-	data, err := marshalYAML(value)
+// The caller must hold the configuration lock.
+func writeConfig(container NameContainer, value any, configOptions *configOptions) error {
+	configFilePath, codec, err := findConfigFile(container, container.ConfigDirPath(), configFileBaseName, configOptions.codecs)
 	if err != nil {
 		return err
 	}
-	if err := os.MkdirAll(container.ConfigDirPath(), 0755); err != nil {
+	if codec == nil {
+		codec = configOptions.codecs[0]
+		configFilePath = filepath.Join(container.ConfigDirPath(), configFileBaseName+codec.FileExtension())
+	}
+	profilesTree, err := readConfigProfilesTree(container, configFilePath, codec)
+	if err != nil {
 		return err
 	}
-	configFilePath := filepath.Join(container.ConfigDirPath(), configFileName)
+	var data []byte
+	if profilesTree == nil && configOptions.migrations == nil {
+		data, err = marshalConfig(codec, value)
+	} else {
+		data, err = marshalConfigTree(codec, value, profilesTree, configOptions)
+	}
+	if err != nil {
+		return err
+	}
+	return writeConfigFile(container, configFilePath, data)
+}
+
+// writeConfigFile writes the configuration file atomically, keeping the mode of the
+// existing file.
+func writeConfigFile(container app.Container, configFilePath string, data []byte) error {
 	fileMode := os.FileMode(0644)
-	// OK to use os.Stat instead of os.Lstat here
-	if fileInfo, err := os.Stat(configFilePath); err == nil {
+	if fileInfo, err := container.FileSystem().Stat(configFilePath); err == nil {
 		fileMode = fileInfo.Mode()
 	}
-	return os.WriteFile(configFilePath, data, fileMode)
+	return writeFileAtomic(container.FileSystem(), configFilePath, data, fileMode)
 }
 
-// Listen listens on the container's port, falling back to defaultPort.
-func Listen(ctx context.Context, container NameContainer, defaultPort uint16) (net.Listener, error) {
-	port, err := container.Port()
+// readConfigProfilesTree reads the profiles section of the configuration file, if the
+// file exists and has one.
+func readConfigProfilesTree(container NameContainer, configFilePath string, codec ConfigCodec) (configTree, error) {
+	data, err := container.FileSystem().ReadFile(configFilePath)
 	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
 		return nil, err
 	}
-	if port == 0 {
-		port = defaultPort
+	// The file is about to be replaced, so it does not matter if it is invalid.
+	tree, _ := codec.Unmarshal(data)
+	profilesTree, _ := getConfigProfilesTree(tree)
+	return profilesTree, nil
+}
+
+// marshalConfigTree marshals the value with the codec, with the profiles section added
+// if it is not nil, and the version set to the current version if there are migrations,
+// so that the file is not migrated when it is read.
+func marshalConfigTree(codec ConfigCodec, value any, profilesTree configTree, configOptions *configOptions) ([]byte, error) {
+	tree, err := valueToConfigTree(value)
+	if err != nil {
+		return nil, err
 	}
-	// Must be 0.0.0.0
-	var listenConfig net.ListenConfig
-	return listenConfig.Listen(ctx, "tcp", fmt.Sprintf("0.0.0.0:%d", port))
+	if configOptions.migrations != nil {
+		tree[configVersionKey] = configOptions.migrations.currentVersion
+	}
+	if profilesTree != nil {
+		tree[configProfilesKey] = profilesTree
+	}
+	return codec.Marshal(tree)
 }
 
-// *** PRIVATE ***
+// withConfigLock calls f while holding the advisory lock on the configuration directory.
+//
+// The directory is created if it does not exist.
+func withConfigLock(ctx context.Context, container NameContainer, configOptions *configOptions, f func() error) (retErr error) {
+	if err := container.FileSystem().MkdirAll(container.ConfigDirPath(), 0755); err != nil {
+		return err
+	}
+	unlock, err := acquireFileLock(
+		ctx,
+		container,
+		filepath.Join(container.ConfigDirPath(), configLockFileName),
+		&lockOptions{
+			timeout: configOptions.lockTimeout,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("could not lock %s configuration directory: %w", container.AppName(), err)
+	}
+	defer func() {
+		retErr = errors.Join(retErr, unlock())
+	}()
+	return f()
+}
 
 // marshalYAML marshals the given value into YAML.
 func marshalYAML(value any) (_ []byte, retErr error) {
@@ -0,0 +1,47 @@
+// Copyright 2025-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package appext
+
+import (
+	"testing"
+
+	"buf.build/go/app"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBindEnv(t *testing.T) {
+	t.Parallel()
+	nameContainer, err := NewNameContainer(
+		app.NewContainer(
+			map[string]string{
+				"FOO_BAR_PORT": "4000",
+				"PORT":         "5000",
+			},
+			nil,
+			nil,
+			nil,
+		),
+		"foo-bar",
+	)
+	require.NoError(t, err)
+	var config struct {
+		Port uint16 `env:"PORT"`
+		Host string `env:"HOST" envDefault:"localhost"`
+	}
+	require.NoError(t, BindEnv(nameContainer, &config))
+	assert.Equal(t, uint16(4000), config.Port)
+	assert.Equal(t, "localhost", config.Host)
+}
@@ -0,0 +1,68 @@
+// Copyright 2025-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package appext
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"buf.build/go/app"
+)
+
+// writeFileAtomic writes the data to the file by writing a temporary file in the same
+// directory and renaming it over the file, so that the file is never partially written.
+//
+// If the file is a symbolic link, the target of the link is written, so that the link
+// is not replaced by a regular file.
+func writeFileAtomic(fileSystem app.FileSystem, filePath string, data []byte, fileMode os.FileMode) (retErr error) {
+	targetFilePath, err := fileSystem.EvalSymlinks(filePath)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	} else {
+		filePath = targetFilePath
+	}
+	suffix := make([]byte, 8)
+	if _, err := rand.Read(suffix); err != nil {
+		return err
+	}
+	tempFilePath := filepath.Join(
+		filepath.Dir(filePath),
+		"."+filepath.Base(filePath)+".tmp-"+hex.EncodeToString(suffix),
+	)
+	file, err := fileSystem.OpenFile(tempFilePath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, fileMode)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if retErr != nil {
+			retErr = errors.Join(retErr, fileSystem.Remove(tempFilePath))
+		}
+	}()
+	_, err = file.Write(data)
+	// Sync if the File supports it, so that the data is on disk before the rename.
+	if syncer, ok := file.(interface{ Sync() error }); ok && err == nil {
+		err = syncer.Sync()
+	}
+	if err := errors.Join(err, file.Close()); err != nil {
+		return err
+	}
+	return fileSystem.Rename(tempFilePath, filePath)
+}
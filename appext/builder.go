@@ -19,6 +19,7 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
 	"time"
 
 	"buf.build/go/app"
@@ -39,6 +40,12 @@ type builder struct {
 
 	interceptors   []Interceptor
 	loggerProvider LoggerProvider
+	dotenv         bool
+
+	profile     string
+	profileFlag bool
+
+	pidFile bool
 }
 
 func newBuilder(appName string, options ...BuilderOption) *builder {
@@ -58,6 +65,9 @@ func (b *builder) BindRoot(flagSet *pflag.FlagSet) {
 	if b.timeoutFlag {
 		flagSet.DurationVar(&b.timeout, "timeout", b.defaultTimeout, `The duration until timing out, setting it to 0 means no timeout`)
 	}
+	if b.profileFlag {
+		flagSet.StringVar(&b.profile, "profile", "", fmt.Sprintf("The configuration profile to use, overriding $%s%s", getAppNameEnvPrefix(b.appName), profileEnvSuffix))
+	}
 
 	// We do not officially support this flag, this is for testing, where we need warnings turned off.
 	flagSet.BoolVar(&b.noWarn, "no-warn", false, "Turn off warn logging")
@@ -85,7 +95,7 @@ func (b *builder) run(
 	ctx context.Context,
 	appContainer app.Container,
 	f func(context.Context, Container) error,
-) error {
+) (retErr error) {
 	logLevel, err := getLogLevel(b.debug, b.noWarn)
 	if err != nil {
 		return err
@@ -94,6 +104,23 @@ func (b *builder) run(
 	if err != nil {
 		return err
 	}
+	if b.dotenv {
+		appContainer, err = loadDotenv(appContainer, b.appName)
+		if err != nil {
+			return err
+		}
+	}
+	if b.profile != "" {
+		appContainer = app.NewContainerForEnv(
+			appContainer,
+			app.NewEnvContainerWithOverrides(
+				appContainer,
+				map[string]string{
+					getAppNameEnvPrefix(b.appName) + profileEnvSuffix: b.profile,
+				},
+			),
+		)
+	}
 	nameContainer, err := newNameContainer(appContainer, b.appName)
 	if err != nil {
 		return err
@@ -103,16 +130,52 @@ func (b *builder) run(
 		return err
 	}
 	container := newContainer(nameContainer, logger, logLevel, logFormat)
+	logContainer(container)
 
 	var cancel context.CancelFunc
 	if b.timeout != 0 {
-		ctx, cancel = context.WithTimeout(ctx, b.timeout)
+		ctx, cancel = app.ContextWithTimeout(ctx, container.Clock(), b.timeout)
 		defer cancel()
 	}
+	if b.pidFile {
+		pidFile := newPIDFile(container)
+		ctx = contextWithPIDFile(ctx, pidFile)
+		defer func() {
+			retErr = errors.Join(retErr, pidFile.remove())
+		}()
+	}
 
 	return f(ctx, container)
 }
 
+// logContainer logs the state of the container at debug level.
+//
+// Only the keys of the environment are logged, as secrets can be in the values of any
+// environment variable, for example in credentials in a DATABASE_URL.
+func logContainer(container Container) {
+	container.Logger().Debug(
+		"container",
+		slog.String("app_name", container.AppName()),
+		slog.String("work_dir", container.WorkDirPath()),
+		slog.String("config_dir", container.ConfigDirPath()),
+		slog.String("cache_dir", container.CacheDirPath()),
+		slog.String("data_dir", container.DataDirPath()),
+		slog.String("state_dir", container.StateDirPath()),
+		slog.String("profile", container.Profile()),
+		slog.Any("env_keys", getEnvKeys(container)),
+	)
+}
+
+// getEnvKeys returns the sorted keys of the environment.
+func getEnvKeys(envContainer app.EnvContainer) []string {
+	var keys []string
+	envContainer.ForEachEnv(func(key string, _ string) {
+		keys = append(keys, key)
+	})
+	slices.Sort(keys)
+	return keys
+}
+
 func getLogLevel(debugFlag bool, noWarnFlag bool) (LogLevel, error) {
 	if debugFlag && noWarnFlag {
 		return 0, errors.New("cannot set both --debug and --no-warn")
@@ -128,8 +191,10 @@ func getLogLevel(debugFlag bool, noWarnFlag bool) (LogLevel, error) {
 
 func defaultLoggerProvider(container NameContainer, logLevel LogLevel, logFormat LogFormat) (*slog.Logger, error) {
 	switch logFormat {
-	case LogFormatText, LogFormatColor:
+	case LogFormatText:
 		return slog.New(slog.NewTextHandler(container.Stderr(), &slog.HandlerOptions{Level: logLevel.SlogLevel()})), nil
+	case LogFormatColor:
+		return slog.New(newColorHandler(container.Stderr(), logLevel.SlogLevel(), isColorEnabled(container))), nil
 	case LogFormatJSON:
 		return slog.New(slog.NewJSONHandler(container.Stderr(), &slog.HandlerOptions{Level: logLevel.SlogLevel()})), nil
 	default:
@@ -137,6 +202,13 @@ func defaultLoggerProvider(container NameContainer, logLevel LogLevel, logFormat
 	}
 }
 
+// isColorEnabled returns true if colors should be printed to stderr.
+//
+// See https://no-color.org.
+func isColorEnabled(container app.Container) bool {
+	return container.IsStderrTerminal() && container.Env("NO_COLOR") == ""
+}
+
 // chainInterceptors consolidates the given interceptors into one.
 // The interceptors are applied in the order they are declared.
 func chainInterceptors(interceptors ...Interceptor) Interceptor {
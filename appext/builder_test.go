@@ -0,0 +1,128 @@
+// Copyright 2025-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package appext
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"buf.build/go/app"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuilderTimeout(t *testing.T) {
+	t.Parallel()
+	clock := app.NewFakeClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	builder := NewBuilder("foo-bar", BuilderWithTimeout(time.Minute))
+	flagSet := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	builder.BindRoot(flagSet)
+	require.NoError(t, flagSet.Parse([]string{"--timeout", "10s"}))
+	runFunc := builder.NewRunFunc(
+		func(ctx context.Context, container Container) error {
+			assert.Equal(t, clock, container.Clock())
+			clock.Advance(9 * time.Second)
+			assert.NoError(t, ctx.Err())
+			clock.Advance(time.Second)
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	)
+	err := runFunc(
+		context.Background(),
+		app.NewContainerForClock(app.NewContainer(nil, nil, nil, nil, "test"), clock),
+	)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestBuilderProfile(t *testing.T) {
+	t.Parallel()
+	builder := NewBuilder("foo-bar", BuilderWithProfile())
+	flagSet := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	builder.BindRoot(flagSet)
+	require.NoError(t, flagSet.Parse([]string{"--profile", "production"}))
+	runFunc := builder.NewRunFunc(
+		func(_ context.Context, container Container) error {
+			assert.Equal(t, "production", container.Profile())
+			assert.Equal(t, "production", container.Env("FOO_BAR_PROFILE"))
+			return nil
+		},
+	)
+	require.NoError(
+		t,
+		runFunc(
+			context.Background(),
+			app.NewContainer(map[string]string{"FOO_BAR_PROFILE": "staging"}, nil, nil, nil, "test"),
+		),
+	)
+}
+
+func TestBuilderDotenv(t *testing.T) {
+	t.Parallel()
+	container := app.NewContainerForWorkDir(
+		app.NewContainer(
+			map[string]string{
+				"FOO_BAR_CONFIG_DIR": "/config",
+				"FROM_ENV":           "env",
+			},
+			nil,
+			nil,
+			nil,
+			"test",
+		),
+		"/work",
+	)
+	fileSystem := container.FileSystem()
+	require.NoError(t, fileSystem.MkdirAll("/config", 0755))
+	require.NoError(t, fileSystem.MkdirAll("/work", 0755))
+	require.NoError(t, fileSystem.WriteFile("/config/.env", []byte("FROM_ENV=config\nFROM_CONFIG=config\nFROM_WORK=config\n"), 0600))
+	require.NoError(t, fileSystem.WriteFile("/work/.env", []byte("FROM_ENV=work\nFROM_WORK=work\n"), 0600))
+	runFunc := NewBuilder("foo-bar", BuilderWithDotenv()).NewRunFunc(
+		func(_ context.Context, container Container) error {
+			assert.Equal(t, "env", container.Env("FROM_ENV"))
+			assert.Equal(t, "config", container.Env("FROM_CONFIG"))
+			assert.Equal(t, "work", container.Env("FROM_WORK"))
+			return nil
+		},
+	)
+	require.NoError(t, runFunc(context.Background(), container))
+}
+
+func TestBuilderDebugLogEnvKeys(t *testing.T) {
+	t.Parallel()
+	builder := NewBuilder("foo-bar")
+	flagSet := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	builder.BindRoot(flagSet)
+	require.NoError(t, flagSet.Parse([]string{"--debug", "--log-format", "text"}))
+	stderr := bytes.NewBuffer(nil)
+	runFunc := builder.NewRunFunc(
+		func(context.Context, Container) error {
+			return nil
+		},
+	)
+	require.NoError(
+		t,
+		runFunc(
+			context.Background(),
+			app.NewContainer(map[string]string{"FOO_BAR_TOKEN": "hunter2", "EDITOR": "vim"}, nil, nil, stderr, "test"),
+		),
+	)
+	assert.Contains(t, stderr.String(), "env_keys=\"[EDITOR FOO_BAR_TOKEN]\"")
+	assert.NotContains(t, stderr.String(), "hunter2")
+	assert.NotContains(t, stderr.String(), "vim")
+}
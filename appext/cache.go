@@ -0,0 +1,399 @@
+// Copyright 2025-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package appext
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	defaultCacheLockTimeout = 10 * time.Second
+	cacheLockFileName       = ".lock"
+	// cacheDirTagFileName is the name of the file that marks a cache directory.
+	//
+	// See https://bford.info/cachedir.
+	cacheDirTagFileName = "CACHEDIR.TAG"
+	// cacheDirTagSignature is the signature that starts a CACHEDIR.TAG file.
+	cacheDirTagSignature = "Signature: 8a477f597d28d172789f06886806bc55"
+	// cacheEntryHeaderSize is the size of the header of entry files, which is the time the
+	// entry was written in Unix nanoseconds, as a big-endian uint64.
+	//
+	// The modification time of entry files is the time the entry was last used.
+	cacheEntryHeaderSize = 8
+)
+
+type cacheOptions struct {
+	maxBytes    int64
+	maxAge      time.Duration
+	lockTimeout time.Duration
+}
+
+func newCacheOptions() *cacheOptions {
+	return &cacheOptions{
+		lockTimeout: defaultCacheLockTimeout,
+	}
+}
+
+type cache struct {
+	container    NameContainer
+	namespace    string
+	dirPath      string
+	cacheOptions *cacheOptions
+}
+
+func newCache(container NameContainer, namespace string, options ...CacheOption) (*cache, error) {
+	if err := validateCacheNamespace(namespace); err != nil {
+		return nil, err
+	}
+	cacheOptions := newCacheOptions()
+	for _, option := range options {
+		option(cacheOptions)
+	}
+	cacheDirPath := container.CacheDirPath()
+	if cacheDirPath == "" {
+		return nil, fmt.Errorf("no cache directory for %s", container.AppName())
+	}
+	dirPath := filepath.Join(cacheDirPath, namespace)
+	if err := container.FileSystem().MkdirAll(dirPath, 0755); err != nil {
+		return nil, err
+	}
+	tagFilePath := filepath.Join(dirPath, cacheDirTagFileName)
+	if _, err := container.FileSystem().Stat(tagFilePath); err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+		tagData := fmt.Sprintf(
+			"%s\n# This file is a cache directory tag created by %s.\n# For information about cache directory tags, see:\n#\thttps://bford.info/cachedir/\n",
+			cacheDirTagSignature,
+			container.AppName(),
+		)
+		if err := writeFileAtomic(container.FileSystem(), tagFilePath, []byte(tagData), 0644); err != nil {
+			return nil, err
+		}
+	}
+	return &cache{
+		container:    container,
+		namespace:    namespace,
+		dirPath:      dirPath,
+		cacheOptions: cacheOptions,
+	}, nil
+}
+
+func (c *cache) DirPath() string {
+	return c.dirPath
+}
+
+func (c *cache) Get(_ context.Context, key string) ([]byte, error) {
+	entryFilePath := c.getEntryFilePath(key)
+	fileSystem := c.container.FileSystem()
+	data, err := fileSystem.ReadFile(entryFilePath)
+	if err != nil {
+		return nil, err
+	}
+	now := c.container.Clock().Now()
+	// Entries without a valid header are treated as missing, and are replaced by Put.
+	if len(data) < cacheEntryHeaderSize || c.isExpired(getCacheEntryWriteTime(data), now) {
+		return nil, &fs.PathError{Op: "get", Path: entryFilePath, Err: fs.ErrNotExist}
+	}
+	data = data[cacheEntryHeaderSize:]
+	// Mark the entry as recently used. The entry may have been evicted concurrently,
+	// which is fine as the data was already read.
+	if err := fileSystem.Chtimes(entryFilePath, now, now); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (c *cache) Put(ctx context.Context, key string, data []byte) error {
+	return c.withLock(
+		ctx,
+		func() error {
+			entryFilePath := c.getEntryFilePath(key)
+			fileSystem := c.container.FileSystem()
+			now := c.container.Clock().Now()
+			entryData := make([]byte, cacheEntryHeaderSize, cacheEntryHeaderSize+len(data))
+			binary.BigEndian.PutUint64(entryData, uint64(now.UnixNano()))
+			if err := writeFileAtomic(fileSystem, entryFilePath, append(entryData, data...), 0644); err != nil {
+				return err
+			}
+			if err := fileSystem.Chtimes(entryFilePath, now, now); err != nil {
+				return err
+			}
+			return c.evict()
+		},
+	)
+}
+
+func (c *cache) Delete(ctx context.Context, key string) error {
+	return c.withLock(
+		ctx,
+		func() error {
+			if err := c.container.FileSystem().Remove(c.getEntryFilePath(key)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return err
+			}
+			return nil
+		},
+	)
+}
+
+func (c *cache) Evict(ctx context.Context) error {
+	return c.withLock(ctx, c.evict)
+}
+
+func (c *cache) Clear(ctx context.Context) error {
+	return c.withLock(
+		ctx,
+		func() error {
+			entryFileInfos, err := c.listEntries()
+			if err != nil {
+				return err
+			}
+			for _, entryFileInfo := range entryFileInfos {
+				if err := c.removeEntry(entryFileInfo); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	)
+}
+
+func (c *cache) Info(context.Context) (CacheInfo, error) {
+	entryFileInfos, err := c.listEntries()
+	if err != nil {
+		return CacheInfo{}, err
+	}
+	cacheInfo := CacheInfo{
+		Namespace: c.namespace,
+		DirPath:   c.dirPath,
+		Entries:   len(entryFileInfos),
+	}
+	for _, entryFileInfo := range entryFileInfos {
+		cacheInfo.Bytes += getCacheEntrySize(entryFileInfo)
+	}
+	return cacheInfo, nil
+}
+
+// evict deletes expired entries, and then the least recently used entries until the
+// total size is within the maximum.
+//
+// The caller must hold the lock.
+func (c *cache) evict() error {
+	if c.cacheOptions.maxAge <= 0 && c.cacheOptions.maxBytes <= 0 {
+		return nil
+	}
+	entryFileInfos, err := c.listEntries()
+	if err != nil {
+		return err
+	}
+	now := c.container.Clock().Now()
+	var totalBytes int64
+	remainingEntryFileInfos := make([]fs.FileInfo, 0, len(entryFileInfos))
+	for _, entryFileInfo := range entryFileInfos {
+		if c.cacheOptions.maxAge > 0 {
+			expired, err := c.isEntryExpired(entryFileInfo, now)
+			if err != nil {
+				return err
+			}
+			if expired {
+				if err := c.removeEntry(entryFileInfo); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		totalBytes += getCacheEntrySize(entryFileInfo)
+		remainingEntryFileInfos = append(remainingEntryFileInfos, entryFileInfo)
+	}
+	if c.cacheOptions.maxBytes <= 0 || totalBytes <= c.cacheOptions.maxBytes {
+		return nil
+	}
+	sort.Slice(
+		remainingEntryFileInfos,
+		func(i int, j int) bool {
+			iModTime := remainingEntryFileInfos[i].ModTime()
+			jModTime := remainingEntryFileInfos[j].ModTime()
+			if iModTime.Equal(jModTime) {
+				return remainingEntryFileInfos[i].Name() < remainingEntryFileInfos[j].Name()
+			}
+			return iModTime.Before(jModTime)
+		},
+	)
+	for _, entryFileInfo := range remainingEntryFileInfos {
+		if totalBytes <= c.cacheOptions.maxBytes {
+			break
+		}
+		if err := c.removeEntry(entryFileInfo); err != nil {
+			return err
+		}
+		totalBytes -= getCacheEntrySize(entryFileInfo)
+	}
+	return nil
+}
+
+// listEntries returns the FileInfos of all entries.
+func (c *cache) listEntries() ([]fs.FileInfo, error) {
+	dirEntries, err := c.container.FileSystem().ReadDir(c.dirPath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	entryFileInfos := make([]fs.FileInfo, 0, len(dirEntries))
+	for _, dirEntry := range dirEntries {
+		// This skips the lock file and temporary files, which start with ".".
+		if dirEntry.IsDir() || strings.HasPrefix(dirEntry.Name(), ".") || dirEntry.Name() == cacheDirTagFileName {
+			continue
+		}
+		entryFileInfo, err := dirEntry.Info()
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		entryFileInfos = append(entryFileInfos, entryFileInfo)
+	}
+	return entryFileInfos, nil
+}
+
+func (c *cache) removeEntry(entryFileInfo fs.FileInfo) error {
+	if err := c.container.FileSystem().Remove(filepath.Join(c.dirPath, entryFileInfo.Name())); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// isEntryExpired reads the header of the entry file, and returns true if the entry is
+// older than the maximum age, or if the header is not valid.
+func (c *cache) isEntryExpired(entryFileInfo fs.FileInfo, now time.Time) (_ bool, retErr error) {
+	file, err := c.container.FileSystem().OpenFile(filepath.Join(c.dirPath, entryFileInfo.Name()), os.O_RDONLY, 0)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+	defer func() {
+		retErr = errors.Join(retErr, file.Close())
+	}()
+	header := make([]byte, cacheEntryHeaderSize)
+	if _, err := io.ReadFull(file, header); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return true, nil
+		}
+		return false, err
+	}
+	return c.isExpired(getCacheEntryWriteTime(header), now), nil
+}
+
+// isExpired returns true if an entry written at the time is older than the maximum age.
+func (c *cache) isExpired(writeTime time.Time, now time.Time) bool {
+	return c.cacheOptions.maxAge > 0 && now.Sub(writeTime) > c.cacheOptions.maxAge
+}
+
+func (c *cache) getEntryFilePath(key string) string {
+	digest := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dirPath, hex.EncodeToString(digest[:]))
+}
+
+// getCacheEntryWriteTime returns the time the entry was written from the header of the data.
+//
+// The data must be at least cacheEntryHeaderSize bytes.
+func getCacheEntryWriteTime(data []byte) time.Time {
+	return time.Unix(0, int64(binary.BigEndian.Uint64(data[:cacheEntryHeaderSize])))
+}
+
+// getCacheEntrySize returns the size of the data of the entry, without the header.
+func getCacheEntrySize(entryFileInfo fs.FileInfo) int64 {
+	return max(entryFileInfo.Size()-cacheEntryHeaderSize, 0)
+}
+
+// withLock calls f while holding the advisory lock on the cache directory.
+func (c *cache) withLock(ctx context.Context, f func() error) (retErr error) {
+	unlock, err := acquireFileLock(
+		ctx,
+		c.container,
+		filepath.Join(c.dirPath, cacheLockFileName),
+		&lockOptions{
+			timeout: c.cacheOptions.lockTimeout,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("could not lock %s cache %s: %w", c.container.AppName(), c.namespace, err)
+	}
+	defer func() {
+		retErr = errors.Join(retErr, unlock())
+	}()
+	return f()
+}
+
+// getCacheNamespaces returns the sorted namespaces of all caches in CacheDirPath.
+func getCacheNamespaces(container NameContainer) ([]string, error) {
+	cacheDirPath := container.CacheDirPath()
+	if cacheDirPath == "" {
+		return nil, nil
+	}
+	dirEntries, err := container.FileSystem().ReadDir(cacheDirPath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var namespaces []string
+	for _, dirEntry := range dirEntries {
+		if !dirEntry.IsDir() || validateCacheNamespace(dirEntry.Name()) != nil {
+			continue
+		}
+		data, err := container.FileSystem().ReadFile(filepath.Join(cacheDirPath, dirEntry.Name(), cacheDirTagFileName))
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		if strings.HasPrefix(string(data), cacheDirTagSignature) {
+			namespaces = append(namespaces, dirEntry.Name())
+		}
+	}
+	// ReadDir returns the entries sorted by name.
+	return namespaces, nil
+}
+
+func validateCacheNamespace(namespace string) error {
+	if namespace == "" {
+		return errors.New("empty cache namespace")
+	}
+	for _, c := range namespace {
+		if !((c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') || c == '-' || c == '_') {
+			return fmt.Errorf("invalid cache namespace: %s", namespace)
+		}
+	}
+	return nil
+}
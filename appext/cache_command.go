@@ -0,0 +1,84 @@
+// Copyright 2025-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package appext
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"text/tabwriter"
+
+	"buf.build/go/app/appcmd"
+)
+
+func runCacheClean(ctx context.Context, container Container) error {
+	namespaces, err := getCacheNamespaces(container)
+	if err != nil {
+		return err
+	}
+	if container.NumArgs() > 0 {
+		argNamespaces := make([]string, 0, container.NumArgs())
+		for i := range container.NumArgs() {
+			namespace := container.Arg(i)
+			if !slices.Contains(namespaces, namespace) {
+				return appcmd.NewInvalidArgumentErrorf("unknown cache namespace: %s", namespace)
+			}
+			argNamespaces = append(argNamespaces, namespace)
+		}
+		namespaces = argNamespaces
+	}
+	for _, namespace := range namespaces {
+		cache, err := newCache(container, namespace)
+		if err != nil {
+			return err
+		}
+		if err := cache.Clear(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func runCacheInfo(ctx context.Context, container Container) error {
+	namespaces, err := getCacheNamespaces(container)
+	if err != nil {
+		return err
+	}
+	tabWriter := tabwriter.NewWriter(container.Stdout(), 0, 0, 2, ' ', 0)
+	if _, err := fmt.Fprintln(tabWriter, "NAMESPACE\tENTRIES\tBYTES\tPATH"); err != nil {
+		return err
+	}
+	for _, namespace := range namespaces {
+		cache, err := newCache(container, namespace)
+		if err != nil {
+			return err
+		}
+		cacheInfo, err := cache.Info(ctx)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(
+			tabWriter,
+			"%s\t%d\t%d\t%s\n",
+			cacheInfo.Namespace,
+			cacheInfo.Entries,
+			cacheInfo.Bytes,
+			cacheInfo.DirPath,
+		); err != nil {
+			return err
+		}
+	}
+	return tabWriter.Flush()
+}
@@ -0,0 +1,163 @@
+// Copyright 2025-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package appext
+
+import (
+	"context"
+	"io/fs"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"buf.build/go/app"
+	"buf.build/go/app/appcmd"
+	"buf.build/go/app/appcmd/appcmdtesting"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	clock := app.NewFakeClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	container := testNewCacheContainer(t, app.NewInMemoryFileSystem(), clock)
+	cache, err := NewCache(container, "blobs", CacheWithMaxBytes(10), CacheWithMaxAge(time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join("cache", "blobs"), cache.DirPath())
+	_, err = cache.Get(ctx, "a")
+	require.ErrorIs(t, err, fs.ErrNotExist)
+
+	require.NoError(t, cache.Put(ctx, "a", []byte("aaaa")))
+	clock.Advance(time.Minute)
+	require.NoError(t, cache.Put(ctx, "b", []byte("bbbb")))
+	clock.Advance(time.Minute)
+	// Mark a as more recently used than b.
+	data, err := cache.Get(ctx, "a")
+	require.NoError(t, err)
+	require.Equal(t, "aaaa", string(data))
+	clock.Advance(time.Minute)
+	// Exceeds the maximum size, so the least recently used entry b is evicted.
+	require.NoError(t, cache.Put(ctx, "c", []byte("cccc")))
+	_, err = cache.Get(ctx, "b")
+	require.ErrorIs(t, err, fs.ErrNotExist)
+	cacheInfo, err := cache.Info(ctx)
+	require.NoError(t, err)
+	require.Equal(t, CacheInfo{Namespace: "blobs", DirPath: cache.DirPath(), Entries: 2, Bytes: 8}, cacheInfo)
+
+	// Entries expire after the maximum age since they were written, even if they were
+	// used since.
+	clock.Advance(58 * time.Minute)
+	_, err = cache.Get(ctx, "a")
+	require.ErrorIs(t, err, fs.ErrNotExist)
+	data, err = cache.Get(ctx, "c")
+	require.NoError(t, err)
+	require.Equal(t, "cccc", string(data))
+	require.NoError(t, cache.Evict(ctx))
+	cacheInfo, err = cache.Info(ctx)
+	require.NoError(t, err)
+	require.Equal(t, CacheInfo{Namespace: "blobs", DirPath: cache.DirPath(), Entries: 1, Bytes: 4}, cacheInfo)
+	clock.Advance(3 * time.Minute)
+	_, err = cache.Get(ctx, "c")
+	require.ErrorIs(t, err, fs.ErrNotExist)
+	require.NoError(t, cache.Evict(ctx))
+	cacheInfo, err = cache.Info(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 0, cacheInfo.Entries)
+
+	require.NoError(t, cache.Put(ctx, "d", []byte("dd")))
+	require.NoError(t, cache.Delete(ctx, "d"))
+	require.NoError(t, cache.Delete(ctx, "d"))
+	require.NoError(t, cache.Put(ctx, "e", []byte("ee")))
+	require.NoError(t, cache.Clear(ctx))
+	cacheInfo, err = cache.Info(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 0, cacheInfo.Entries)
+
+	_, err = NewCache(container, "../blobs")
+	require.Error(t, err)
+}
+
+func TestCacheCommand(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	fileSystem := app.NewInMemoryFileSystem()
+	container := testNewCacheContainer(t, fileSystem, app.NewClockForOS())
+	blobsCache, err := NewCache(container, "blobs")
+	require.NoError(t, err)
+	require.NoError(t, blobsCache.Put(ctx, "a", []byte("aaaa")))
+	require.NoError(t, blobsCache.Put(ctx, "b", []byte("bb")))
+	modulesCache, err := NewCache(container, "modules")
+	require.NoError(t, err)
+	require.NoError(t, modulesCache.Put(ctx, "a", []byte("a")))
+	// Directories that are not caches are ignored.
+	require.NoError(t, fileSystem.MkdirAll(filepath.Join("cache", "crash"), 0755))
+
+	run := func(expectedExitCode int, expectedStdout string, args ...string) {
+		options := []appcmdtesting.RunOption{
+			appcmdtesting.WithFileSystem(fileSystem),
+			appcmdtesting.WithEnv(
+				func(string) map[string]string {
+					return map[string]string{"FOO_BAR_CACHE_DIR": "cache"}
+				},
+			),
+			appcmdtesting.WithArgs(args...),
+			appcmdtesting.WithExpectedExitCode(expectedExitCode),
+		}
+		if expectedExitCode == 0 {
+			options = append(options, appcmdtesting.WithExpectedStdout(expectedStdout))
+		}
+		appcmdtesting.Run(
+			t,
+			func(use string) *appcmd.Command {
+				return NewCacheCommand(use, NewBuilder("foo-bar"))
+			},
+			options...,
+		)
+	}
+	blobsDirPath := filepath.Join("cache", "blobs")
+	modulesDirPath := filepath.Join("cache", "modules")
+	run(
+		0,
+		`NAMESPACE  ENTRIES  BYTES  PATH
+blobs      2        6      `+blobsDirPath+`
+modules    1        1      `+modulesDirPath,
+		"info",
+	)
+	run(1, "", "clean", "crash")
+	run(0, "", "clean", "blobs")
+	run(
+		0,
+		`NAMESPACE  ENTRIES  BYTES  PATH
+blobs      0        0      `+blobsDirPath+`
+modules    1        1      `+modulesDirPath,
+		"info",
+	)
+	run(0, "", "clean")
+	cacheInfo, err := modulesCache.Info(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 0, cacheInfo.Entries)
+}
+
+func testNewCacheContainer(t *testing.T, fileSystem app.FileSystem, clock app.Clock) NameContainer {
+	baseContainer := app.NewContainerForClock(
+		app.NewContainerForFileSystem(
+			app.NewContainer(map[string]string{"FOO_BAR_CACHE_DIR": "cache"}, nil, nil, nil, "test"),
+			fileSystem,
+		),
+		clock,
+	)
+	container, err := NewNameContainer(baseContainer, "foo-bar")
+	require.NoError(t, err)
+	return container
+}
@@ -0,0 +1,216 @@
+// Copyright 2025-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package appext
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// colorHandlerMessageWidth is the width that messages are padded to when
+	// followed by attributes, so that attributes are aligned.
+	colorHandlerMessageWidth = 40
+	// colorHandlerLevelWidth is the width that levels are padded to.
+	colorHandlerLevelWidth = 5
+	colorHandlerTimeFormat = "15:04:05.000"
+
+	ansiReset   = "\x1b[0m"
+	ansiDim     = "\x1b[2m"
+	ansiRed     = "\x1b[31m"
+	ansiGreen   = "\x1b[32m"
+	ansiYellow  = "\x1b[33m"
+	ansiBlue    = "\x1b[34m"
+	ansiMagenta = "\x1b[35m"
+	ansiCyan    = "\x1b[36m"
+)
+
+// colorHandler is a human-oriented slog.Handler.
+//
+// Each record is printed on one line, with the level, the message, and then the
+// attributes, aligned to colorHandlerMessageWidth. Groups are printed as
+// dot-separated key prefixes.
+type colorHandler struct {
+	writer io.Writer
+	// lock serializes writes to writer, and is shared with the handlers from WithAttrs
+	// and WithGroup, as with slog.TextHandler.
+	lock  *sync.Mutex
+	level slog.Leveler
+	color bool
+	// attrs are the pre-formatted attributes from WithAttrs.
+	attrs []byte
+	// groupPrefix is the prefix for keys from WithGroup, ending in ".".
+	groupPrefix string
+}
+
+func newColorHandler(writer io.Writer, level slog.Leveler, color bool) *colorHandler {
+	return &colorHandler{
+		writer: writer,
+		lock:   &sync.Mutex{},
+		level:  level,
+		color:  color,
+	}
+}
+
+func (h *colorHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+func (h *colorHandler) Handle(_ context.Context, record slog.Record) error {
+	var attrs []byte
+	record.Attrs(
+		func(attr slog.Attr) bool {
+			attrs = h.appendAttr(attrs, h.groupPrefix, attr)
+			return true
+		},
+	)
+	var buffer []byte
+	if !record.Time.IsZero() {
+		buffer = h.appendColored(buffer, ansiDim, record.Time.Format(colorHandlerTimeFormat))
+		buffer = append(buffer, ' ')
+	}
+	buffer = h.appendColored(buffer, levelColor(record.Level), padRight(record.Level.String(), colorHandlerLevelWidth))
+	buffer = append(buffer, ' ')
+	if len(h.attrs) > 0 || len(attrs) > 0 {
+		buffer = append(buffer, padRight(record.Message, colorHandlerMessageWidth)...)
+		buffer = append(buffer, h.attrs...)
+		buffer = append(buffer, attrs...)
+	} else {
+		buffer = append(buffer, record.Message...)
+	}
+	buffer = append(buffer, '\n')
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	_, err := h.writer.Write(buffer)
+	return err
+}
+
+func (h *colorHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	clone := h.clone()
+	for _, attr := range attrs {
+		clone.attrs = clone.appendAttr(clone.attrs, clone.groupPrefix, attr)
+	}
+	return clone
+}
+
+func (h *colorHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	clone := h.clone()
+	clone.groupPrefix += name + "."
+	return clone
+}
+
+func (h *colorHandler) clone() *colorHandler {
+	return &colorHandler{
+		writer:      h.writer,
+		lock:        h.lock,
+		level:       h.level,
+		color:       h.color,
+		attrs:       slices.Clip(h.attrs),
+		groupPrefix: h.groupPrefix,
+	}
+}
+
+func (h *colorHandler) appendAttr(buffer []byte, prefix string, attr slog.Attr) []byte {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return buffer
+	}
+	if attr.Value.Kind() == slog.KindGroup {
+		groupAttrs := attr.Value.Group()
+		if len(groupAttrs) == 0 {
+			return buffer
+		}
+		// Inline groups with empty keys.
+		if attr.Key != "" {
+			prefix += attr.Key + "."
+		}
+		for _, groupAttr := range groupAttrs {
+			buffer = h.appendAttr(buffer, prefix, groupAttr)
+		}
+		return buffer
+	}
+	buffer = append(buffer, ' ')
+	buffer = h.appendColored(buffer, ansiCyan, prefix+attr.Key)
+	buffer = h.appendColored(buffer, ansiDim, "=")
+	return append(buffer, formatValue(attr.Value)...)
+}
+
+func (h *colorHandler) appendColored(buffer []byte, color string, s string) []byte {
+	if !h.color {
+		return append(buffer, s...)
+	}
+	buffer = append(buffer, color...)
+	buffer = append(buffer, s...)
+	return append(buffer, ansiReset...)
+}
+
+func levelColor(level slog.Level) string {
+	switch {
+	case level >= slog.LevelError:
+		return ansiRed
+	case level >= slog.LevelWarn:
+		return ansiYellow
+	case level >= slog.LevelInfo:
+		return ansiGreen
+	case level >= slog.LevelDebug:
+		return ansiBlue
+	default:
+		return ansiMagenta
+	}
+}
+
+func formatValue(value slog.Value) string {
+	s := value.String()
+	if value.Kind() == slog.KindTime {
+		s = value.Time().Format(time.RFC3339Nano)
+	}
+	if needsQuoting(s) {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+func needsQuoting(s string) bool {
+	if s == "" {
+		return true
+	}
+	for _, r := range s {
+		if r == '"' || r == '=' || unicode.IsSpace(r) || !unicode.IsPrint(r) {
+			return true
+		}
+	}
+	return false
+}
+
+func padRight(s string, width int) string {
+	if length := utf8.RuneCountInString(s); length < width {
+		return s + strings.Repeat(" ", width-length)
+	}
+	return s
+}
@@ -0,0 +1,98 @@
+// Copyright 2025-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package appext
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+
+	"buf.build/go/app"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestColorHandler(t *testing.T) {
+	t.Parallel()
+	buffer := bytes.NewBuffer(nil)
+	logger := slog.New(newColorHandler(buffer, slog.LevelInfo, false))
+	logger.Debug("hidden")
+	logger.Info("no attrs")
+	logger.With("a", 1).WithGroup("g").Warn(
+		"with attrs",
+		"b", "two words",
+		slog.Group("h", "c", true),
+		"err", errors.New("failed"),
+	)
+	lines := strings.Split(strings.TrimSuffix(buffer.String(), "\n"), "\n")
+	require.Len(t, lines, 2)
+	// Strip the time.
+	_, line, _ := strings.Cut(lines[0], " ")
+	assert.Equal(t, "INFO  no attrs", line)
+	_, line, _ = strings.Cut(lines[1], " ")
+	assert.Equal(
+		t,
+		"WARN  with attrs"+strings.Repeat(" ", colorHandlerMessageWidth-len("with attrs"))+
+			` a=1 g.b="two words" g.h.c=true g.err=failed`,
+		line,
+	)
+	assert.NotContains(t, buffer.String(), "\x1b[")
+
+	buffer.Reset()
+	logger = slog.New(newColorHandler(buffer, slog.LevelInfo, true))
+	logger.Error("colored", "a", 1)
+	assert.Contains(t, buffer.String(), ansiRed+"ERROR"+ansiReset)
+	assert.Contains(t, buffer.String(), ansiCyan+"a"+ansiReset)
+}
+
+func TestColorHandlerConcurrent(t *testing.T) {
+	t.Parallel()
+	buffer := bytes.NewBuffer(nil)
+	logger := slog.New(newColorHandler(buffer, slog.LevelInfo, false))
+	var waitGroup sync.WaitGroup
+	for i := range 10 {
+		// Derived handlers share the lock on the writer.
+		logger := logger.With("i", i)
+		waitGroup.Go(
+			func() {
+				for range 100 {
+					logger.WithGroup("g").Info("message", "a", 1)
+				}
+			},
+		)
+	}
+	waitGroup.Wait()
+	lines := strings.Split(strings.TrimSuffix(buffer.String(), "\n"), "\n")
+	require.Len(t, lines, 1000)
+	for _, line := range lines {
+		assert.Contains(t, line, " g.a=1")
+	}
+}
+
+func TestIsColorEnabled(t *testing.T) {
+	t.Parallel()
+	newContainer := func(env map[string]string, isStderrTerminal bool) app.Container {
+		return app.NewContainerForTerminal(
+			app.NewContainer(env, nil, nil, nil, "test"),
+			app.NewTerminalContainer(false, false, isStderrTerminal, 80, 24),
+		)
+	}
+	assert.True(t, isColorEnabled(newContainer(nil, true)))
+	assert.False(t, isColorEnabled(newContainer(nil, false)))
+	assert.False(t, isColorEnabled(newContainer(map[string]string{"NO_COLOR": "1"}, true)))
+}
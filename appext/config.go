@@ -0,0 +1,424 @@
+// Copyright 2025-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package appext
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+const defaultConfigLockTimeout = 10 * time.Second
+
+type configOptions struct {
+	flagSet         *pflag.FlagSet
+	projectFileName string
+	codecs          []ConfigCodec
+	lockTimeout     time.Duration
+	migrations      *configMigrations
+	writeMigrated   bool
+	interpolateEnv  bool
+	profile         *string
+}
+
+func newConfigOptions(appName string) *configOptions {
+	return &configOptions{
+		projectFileName: "." + appName + ".yaml",
+		codecs: []ConfigCodec{
+			NewYAMLConfigCodec(),
+			NewJSONConfigCodec(),
+			NewTOMLConfigCodec(),
+		},
+		lockTimeout: defaultConfigLockTimeout,
+	}
+}
+
+// configTree is a generic configuration tree.
+//
+// Maps are always map[string]any.
+type configTree = map[string]any
+
+func loadConfig(container NameContainer, value any, configOptions *configOptions) (ConfigSources, error) {
+	defaultTree, err := valueToConfigTree(value)
+	if err != nil {
+		return nil, err
+	}
+	valueType := reflect.TypeOf(value)
+	merged := make(configTree)
+	sources := make(ConfigSources)
+	mergeConfigTree(merged, defaultTree, "", ConfigSource{Layer: ConfigLayerDefault}, sources)
+	if err := mergeConfigFiles(container, merged, sources, valueType, configOptions); err != nil {
+		return nil, err
+	}
+	// Keys of the type cover fields with nil or omitted default values, and keys of the
+	// default value cover map entries.
+	leafKeys := mergeConfigLeafKeys(getConfigTypeLeafKeys(valueType), getConfigTreeLeafKeys(defaultTree, ""))
+	mergeConfigEnv(container, merged, sources, leafKeys, valueType)
+	if configOptions.flagSet != nil {
+		mergeConfigFlags(configOptions.flagSet, merged, sources, leafKeys, valueType)
+	}
+	data, err := yaml.Marshal(merged)
+	if err != nil {
+		return nil, err
+	}
+	if err := unmarshalYAMLStrict(data, value); err != nil {
+		return nil, fmt.Errorf("invalid %s configuration: %w", container.AppName(), err)
+	}
+	return sources, nil
+}
+
+func mergeConfigFiles(
+	container NameContainer,
+	merged configTree,
+	sources ConfigSources,
+	valueType reflect.Type,
+	configOptions *configOptions,
+) error {
+	profile := getConfigProfile(container, configOptions)
+	var profileFound bool
+	var systemConfigDirPaths []string
+	for _, configDirPath := range container.ConfigDirPaths() {
+		if configDirPath != container.ConfigDirPath() {
+			systemConfigDirPaths = append(systemConfigDirPaths, configDirPath)
+		}
+	}
+	// Merge in reverse order of precedence.
+	for i := len(systemConfigDirPaths) - 1; i >= 0; i-- {
+		found, err := mergeConfigDir(container, systemConfigDirPaths[i], ConfigLayerSystem, profile, merged, sources, valueType, configOptions)
+		if err != nil {
+			return err
+		}
+		profileFound = profileFound || found
+	}
+	if configDirPath := container.ConfigDirPath(); configDirPath != "" {
+		found, err := mergeConfigDir(container, configDirPath, ConfigLayerUser, profile, merged, sources, valueType, configOptions)
+		if err != nil {
+			return err
+		}
+		profileFound = profileFound || found
+	}
+	projectFilePath, err := findProjectConfigFile(container, configOptions.projectFileName)
+	if err != nil {
+		return err
+	}
+	if projectFilePath != "" {
+		codec, err := getConfigCodecForFilePath(projectFilePath, configOptions.codecs)
+		if err != nil {
+			return err
+		}
+		found, err := mergeConfigFile(container, projectFilePath, codec, ConfigLayerProject, profile, merged, sources, valueType, configOptions)
+		if err != nil {
+			return err
+		}
+		profileFound = profileFound || found
+	}
+	if profile != "" && !profileFound {
+		return fmt.Errorf("profile %q not found in any %s configuration file", profile, container.AppName())
+	}
+	return nil
+}
+
+func mergeConfigDir(
+	container NameContainer,
+	configDirPath string,
+	layer ConfigLayer,
+	profile string,
+	merged configTree,
+	sources ConfigSources,
+	valueType reflect.Type,
+	configOptions *configOptions,
+) (bool, error) {
+	configFilePath, codec, err := findConfigFile(container, configDirPath, configFileBaseName, configOptions.codecs)
+	if err != nil || codec == nil {
+		return false, err
+	}
+	return mergeConfigFile(container, configFilePath, codec, layer, profile, merged, sources, valueType, configOptions)
+}
+
+// mergeConfigFile merges the configuration file into merged, with the profile applied.
+//
+// Returns true if the profile was found in the file.
+func mergeConfigFile(
+	container NameContainer,
+	configFilePath string,
+	codec ConfigCodec,
+	layer ConfigLayer,
+	profile string,
+	merged configTree,
+	sources ConfigSources,
+	valueType reflect.Type,
+	configOptions *configOptions,
+) (bool, error) {
+	data, err := container.FileSystem().ReadFile(configFilePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+		return false, fmt.Errorf("could not read %s configuration file at %s: %w", container.AppName(), configFilePath, err)
+	}
+	tree, err := codec.Unmarshal(data)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s configuration file at %s: %w", container.AppName(), configFilePath, err)
+	}
+	tree = normalizeConfigTree(tree)
+	if configOptions.migrations != nil {
+		migrated, err := configOptions.migrations.migrate(container.AppName(), configFilePath, tree)
+		if err != nil {
+			return false, err
+		}
+		if migrated && configOptions.writeMigrated && layer == ConfigLayerUser {
+			if err := writeMigratedConfig(container, configFilePath, codec, configOptions); err != nil {
+				return false, err
+			}
+		}
+	}
+	found, err := applyConfigProfile(tree, profile)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s configuration file at %s: %w", container.AppName(), configFilePath, err)
+	}
+	if configOptions.interpolateEnv {
+		if err := interpolateConfigTree(container, tree, valueType, getConfigQuotedStrings(codec, data)); err != nil {
+			return false, fmt.Errorf("invalid %s configuration file at %s: %w", container.AppName(), configFilePath, err)
+		}
+	}
+	mergeConfigTree(merged, tree, "", ConfigSource{Layer: layer, Path: configFilePath}, sources)
+	return found, nil
+}
+
+// findProjectConfigFile walks up from the working directory to find the project configuration file.
+//
+// Returns "" if no file is found.
+func findProjectConfigFile(container NameContainer, projectFileName string) (string, error) {
+	dirPath := filepath.Clean(container.WorkDirPath())
+	for {
+		projectFilePath := filepath.Join(dirPath, projectFileName)
+		fileInfo, err := container.FileSystem().Stat(projectFilePath)
+		if err == nil && !fileInfo.IsDir() {
+			return projectFilePath, nil
+		}
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return "", err
+		}
+		parentDirPath := filepath.Dir(dirPath)
+		if parentDirPath == dirPath {
+			return "", nil
+		}
+		dirPath = parentDirPath
+	}
+}
+
+// mergeConfigEnv merges the environment variables for the leaf keys into merged.
+//
+// Values are parsed with parseConfigString for the type of the key in values of valueType.
+func mergeConfigEnv(container NameContainer, merged configTree, sources ConfigSources, leafKeys []string, valueType reflect.Type) {
+	envPrefix := getAppNameEnvPrefix(container.AppName())
+	for _, leafKey := range leafKeys {
+		envKey := envPrefix + strings.ToUpper(strings.NewReplacer(".", "_", "-", "_").Replace(leafKey))
+		if envValue := container.Env(envKey); envValue != "" {
+			leafType, _ := getConfigKeyType(valueType, leafKey)
+			setConfigTreeValue(merged, leafKey, parseConfigString(envValue, leafType), ConfigSource{Layer: ConfigLayerEnv, Path: envKey}, sources)
+		}
+	}
+}
+
+// mergeConfigFlags merges the set flags for the leaf keys into merged.
+//
+// Values are parsed with parseConfigString for the type of the key in values of valueType.
+func mergeConfigFlags(flagSet *pflag.FlagSet, merged configTree, sources ConfigSources, leafKeys []string, valueType reflect.Type) {
+	flagNameToLeafKey := make(map[string]string, len(leafKeys))
+	for _, leafKey := range leafKeys {
+		flagNameToLeafKey[strings.NewReplacer(".", "-", "_", "-").Replace(leafKey)] = leafKey
+	}
+	flagSet.Visit(
+		func(flag *pflag.Flag) {
+			leafKey, ok := flagNameToLeafKey[flag.Name]
+			if !ok {
+				return
+			}
+			leafType, _ := getConfigKeyType(valueType, leafKey)
+			var value any
+			if sliceValue, ok := flag.Value.(pflag.SliceValue); ok {
+				elemType := getConfigElemType(leafType)
+				var elements []any
+				for _, element := range sliceValue.GetSlice() {
+					elements = append(elements, parseConfigString(element, elemType))
+				}
+				value = elements
+			} else {
+				value = parseConfigString(flag.Value.String(), leafType)
+			}
+			setConfigTreeValue(merged, leafKey, value, ConfigSource{Layer: ConfigLayerFlag, Path: flag.Name}, sources)
+		},
+	)
+}
+
+// valueToConfigTree converts the value to a configTree by round-tripping through YAML.
+func valueToConfigTree(value any) (configTree, error) {
+	data, err := yaml.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var tree configTree
+	if err := yaml.Unmarshal(data, &tree); err != nil {
+		return nil, err
+	}
+	return normalizeConfigTree(tree), nil
+}
+
+// normalizeConfigTree converts all nested maps to map[string]any.
+func normalizeConfigTree(tree configTree) configTree {
+	normalized, _ := normalizeConfigValue(tree).(configTree)
+	if normalized == nil {
+		normalized = make(configTree)
+	}
+	return normalized
+}
+
+func normalizeConfigValue(value any) any {
+	switch t := value.(type) {
+	case map[string]any:
+		m := make(configTree, len(t))
+		for key, elem := range t {
+			m[key] = normalizeConfigValue(elem)
+		}
+		return m
+	case map[any]any:
+		m := make(configTree, len(t))
+		for key, elem := range t {
+			m[fmt.Sprint(key)] = normalizeConfigValue(elem)
+		}
+		return m
+	case []any:
+		s := make([]any, len(t))
+		for i, elem := range t {
+			s[i] = normalizeConfigValue(elem)
+		}
+		return s
+	default:
+		return value
+	}
+}
+
+// mergeConfigTree merges src into dst, recording the source of each leaf set.
+//
+// Maps are merged, and all other values are replaced.
+func mergeConfigTree(dst configTree, src configTree, prefix string, source ConfigSource, sources ConfigSources) {
+	for key, value := range src {
+		fullKey := joinConfigKey(prefix, key)
+		if srcMap, ok := value.(configTree); ok {
+			dstMap, ok := dst[key].(configTree)
+			if !ok {
+				deleteConfigSources(sources, fullKey)
+				dstMap = make(configTree)
+				dst[key] = dstMap
+			}
+			mergeConfigTree(dstMap, srcMap, fullKey, source, sources)
+			continue
+		}
+		deleteConfigSources(sources, fullKey)
+		dst[key] = value
+		sources[fullKey] = source
+	}
+}
+
+// setConfigTreeValue sets the value at the dotted key, creating intermediate maps as needed.
+func setConfigTreeValue(tree configTree, key string, value any, source ConfigSource, sources ConfigSources) {
+	parts := strings.Split(key, ".")
+	for _, part := range parts[:len(parts)-1] {
+		child, ok := tree[part].(configTree)
+		if !ok {
+			child = make(configTree)
+			tree[part] = child
+		}
+		tree = child
+	}
+	deleteConfigSources(sources, key)
+	tree[parts[len(parts)-1]] = value
+	sources[key] = source
+}
+
+// deleteConfigSources deletes the sources for the key and all keys nested under it.
+func deleteConfigSources(sources ConfigSources, key string) {
+	for sourceKey := range sources {
+		if sourceKey == key || strings.HasPrefix(sourceKey, key+".") {
+			delete(sources, sourceKey)
+		}
+	}
+}
+
+// getConfigTreeLeafKeys returns the sorted dotted keys of all non-map values in the tree.
+func getConfigTreeLeafKeys(tree configTree, prefix string) []string {
+	var leafKeys []string
+	for key, value := range tree {
+		fullKey := joinConfigKey(prefix, key)
+		if child, ok := value.(configTree); ok && len(child) > 0 {
+			leafKeys = append(leafKeys, getConfigTreeLeafKeys(child, fullKey)...)
+			continue
+		}
+		leafKeys = append(leafKeys, fullKey)
+	}
+	sort.Strings(leafKeys)
+	return leafKeys
+}
+
+// mergeConfigLeafKeys returns the sorted union of the sorted leaf keys, without the keys
+// that are prefixes of other keys, as these are not leaves.
+func mergeConfigLeafKeys(leafKeys ...[]string) []string {
+	var merged []string
+	for _, keys := range leafKeys {
+		merged = append(merged, keys...)
+	}
+	sort.Strings(merged)
+	merged = slices.Compact(merged)
+	var filtered []string
+	for _, key := range merged {
+		if !slices.ContainsFunc(merged, func(other string) bool { return strings.HasPrefix(other, key+".") }) {
+			filtered = append(filtered, key)
+		}
+	}
+	return filtered
+}
+
+// parseConfigScalar parses the string as a YAML scalar or flow sequence, falling back to the string itself.
+func parseConfigScalar(s string) any {
+	var value any
+	if err := yaml.Unmarshal([]byte(s), &value); err != nil {
+		return s
+	}
+	switch value.(type) {
+	case map[string]any, map[any]any, nil:
+		return s
+	case []any:
+		return normalizeConfigValue(value)
+	default:
+		return value
+	}
+}
+
+func joinConfigKey(prefix string, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
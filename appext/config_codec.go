@@ -0,0 +1,271 @@
+// Copyright 2025-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package appext
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// typedConfigCodec is implemented by ConfigCodecs that can marshal and unmarshal typed
+// values directly, preserving field order on marshal and positions in errors on unmarshal.
+type typedConfigCodec interface {
+	marshalValue(value any) ([]byte, error)
+	unmarshalValue(data []byte, value any, strict bool) error
+}
+
+type yamlConfigCodec struct{}
+
+func (yamlConfigCodec) FileExtension() string {
+	return ".yaml"
+}
+
+func (yamlConfigCodec) Marshal(tree map[string]any) ([]byte, error) {
+	return marshalYAML(tree)
+}
+
+func (yamlConfigCodec) Unmarshal(data []byte) (map[string]any, error) {
+	var tree map[string]any
+	if err := unmarshalYAMLNonStrict(data, &tree); err != nil {
+		return nil, err
+	}
+	return normalizeConfigTree(tree), nil
+}
+
+func (yamlConfigCodec) marshalValue(value any) ([]byte, error) {
+	return marshalYAML(value)
+}
+
+func (yamlConfigCodec) unmarshalValue(data []byte, value any, strict bool) error {
+	if strict {
+		return unmarshalYAMLStrict(data, value)
+	}
+	return unmarshalYAMLNonStrict(data, value)
+}
+
+type jsonConfigCodec struct{}
+
+func (jsonConfigCodec) FileExtension() string {
+	return ".json"
+}
+
+func (jsonConfigCodec) Marshal(tree map[string]any) ([]byte, error) {
+	data, err := json.MarshalIndent(tree, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+func (jsonConfigCodec) Unmarshal(data []byte) (map[string]any, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return make(map[string]any), nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var tree map[string]any
+	if err := decoder.Decode(&tree); err != nil {
+		return nil, fmt.Errorf("could not unmarshal as JSON: %w", err)
+	}
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		return nil, errors.New("could not unmarshal as JSON: unexpected data after top-level value")
+	}
+	normalized, _ := normalizeJSONNumbers(tree).(map[string]any)
+	return normalizeConfigTree(normalized), nil
+}
+
+// normalizeJSONNumbers converts json.Numbers to int64 if they are integers, and float64 otherwise.
+func normalizeJSONNumbers(value any) any {
+	switch t := value.(type) {
+	case map[string]any:
+		for key, elem := range t {
+			t[key] = normalizeJSONNumbers(elem)
+		}
+		return t
+	case []any:
+		for i, elem := range t {
+			t[i] = normalizeJSONNumbers(elem)
+		}
+		return t
+	case json.Number:
+		if i, err := t.Int64(); err == nil {
+			return i
+		}
+		if f, err := t.Float64(); err == nil {
+			return f
+		}
+		return t.String()
+	default:
+		return value
+	}
+}
+
+type tomlConfigCodec struct{}
+
+func (tomlConfigCodec) FileExtension() string {
+	return ".toml"
+}
+
+func (tomlConfigCodec) Marshal(tree map[string]any) ([]byte, error) {
+	buffer := bytes.NewBuffer(nil)
+	if err := toml.NewEncoder(buffer).Encode(tree); err != nil {
+		return nil, fmt.Errorf("could not marshal as TOML: %w", err)
+	}
+	return buffer.Bytes(), nil
+}
+
+func (tomlConfigCodec) Unmarshal(data []byte) (map[string]any, error) {
+	var tree map[string]any
+	if err := toml.Unmarshal(data, &tree); err != nil {
+		return nil, fmt.Errorf("could not unmarshal as TOML: %w", err)
+	}
+	normalized, _ := normalizeTOMLValue(tree).(map[string]any)
+	if normalized == nil {
+		normalized = make(map[string]any)
+	}
+	return normalized, nil
+}
+
+// normalizeTOMLValue converts arrays of tables to []any, and local date-times, dates, and
+// times to strings, as they have no time zone.
+func normalizeTOMLValue(value any) any {
+	switch t := value.(type) {
+	case map[string]any:
+		for key, elem := range t {
+			t[key] = normalizeTOMLValue(elem)
+		}
+		return t
+	case []map[string]any:
+		s := make([]any, len(t))
+		for i, elem := range t {
+			s[i] = normalizeTOMLValue(elem)
+		}
+		return s
+	case []any:
+		for i, elem := range t {
+			t[i] = normalizeTOMLValue(elem)
+		}
+		return t
+	case time.Time:
+		// Local values are decoded with these time zone names.
+		switch t.Location().String() {
+		case "datetime-local":
+			return t.Format("2006-01-02T15:04:05.999999999")
+		case "date-local":
+			return t.Format(time.DateOnly)
+		case "time-local":
+			return t.Format("15:04:05.999999999")
+		default:
+			return t
+		}
+	default:
+		return value
+	}
+}
+
+// findConfigFile finds the configuration file with the base name in the directory for
+// any of the codecs.
+//
+// Returns "" and a nil codec if no file exists.
+// Returns error if files exist for more than one codec.
+func findConfigFile(container NameContainer, dirPath string, baseName string, codecs []ConfigCodec) (string, ConfigCodec, error) {
+	var foundFilePaths []string
+	var foundCodec ConfigCodec
+	for _, codec := range codecs {
+		filePath := filepath.Join(dirPath, baseName+codec.FileExtension())
+		if _, err := container.FileSystem().Stat(filePath); err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return "", nil, err
+		}
+		foundFilePaths = append(foundFilePaths, filePath)
+		foundCodec = codec
+	}
+	switch len(foundFilePaths) {
+	case 0:
+		return "", nil, nil
+	case 1:
+		return foundFilePaths[0], foundCodec, nil
+	default:
+		return "", nil, fmt.Errorf(
+			"multiple %s configuration files found, only one may exist: %s",
+			container.AppName(),
+			strings.Join(foundFilePaths, ", "),
+		)
+	}
+}
+
+// getConfigCodecForFilePath returns the codec for the extension of the file path.
+func getConfigCodecForFilePath(filePath string, codecs []ConfigCodec) (ConfigCodec, error) {
+	extension := filepath.Ext(filePath)
+	for _, codec := range codecs {
+		if codec.FileExtension() == extension {
+			return codec, nil
+		}
+	}
+	return nil, fmt.Errorf("no configuration codec for file extension %q of %s", extension, filePath)
+}
+
+// unmarshalConfig unmarshals the data with the codec into the value.
+//
+// If the data length is 0, this is a no-op.
+func unmarshalConfig(codec ConfigCodec, data []byte, value any, strict bool) error {
+	if len(data) == 0 {
+		return nil
+	}
+	if typedCodec, ok := codec.(typedConfigCodec); ok {
+		return typedCodec.unmarshalValue(data, value, strict)
+	}
+	tree, err := codec.Unmarshal(data)
+	if err != nil {
+		return err
+	}
+	return unmarshalConfigTree(tree, value, strict)
+}
+
+// unmarshalConfigTree unmarshals the tree into the value.
+func unmarshalConfigTree(tree map[string]any, value any, strict bool) error {
+	yamlData, err := yaml.Marshal(tree)
+	if err != nil {
+		return err
+	}
+	if strict {
+		return unmarshalYAMLStrict(yamlData, value)
+	}
+	return unmarshalYAMLNonStrict(yamlData, value)
+}
+
+// marshalConfig marshals the value with the codec.
+func marshalConfig(codec ConfigCodec, value any) ([]byte, error) {
+	if typedCodec, ok := codec.(typedConfigCodec); ok {
+		return typedCodec.marshalValue(value)
+	}
+	tree, err := valueToConfigTree(value)
+	if err != nil {
+		return nil, err
+	}
+	return codec.Marshal(tree)
+}
@@ -0,0 +1,201 @@
+// Copyright 2025-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package appext
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"buf.build/go/app"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadConfigCodecs(t *testing.T) {
+	t.Parallel()
+	for _, testCase := range []struct {
+		fileName string
+		data     string
+	}{
+		{fileName: "config.yaml", data: "name: foo\nserver:\n  port: 8080\n"},
+		{fileName: "config.json", data: `{"name": "foo", "server": {"port": 8080}}`},
+		{fileName: "config.toml", data: "name = \"foo\"\n\n[server]\nport = 8080\n"},
+	} {
+		t.Run(testCase.fileName, func(t *testing.T) {
+			t.Parallel()
+			container := testNewCodecContainer(t, map[string]string{testCase.fileName: testCase.data})
+			config := &testLayeredConfig{}
+			require.NoError(t, ReadConfig(container, config))
+			assert.Equal(t, &testLayeredConfig{Name: "foo", Server: testServerConfig{Port: 8080}}, config)
+
+			// Strictness is preserved for all codecs.
+			container = testNewCodecContainer(t, map[string]string{testCase.fileName: testCase.data})
+			nameOnlyConfig := &struct {
+				Name string `yaml:"name"`
+			}{}
+			require.Error(t, ReadConfig(container, nameOnlyConfig))
+			require.NoError(t, ReadConfigNonStrict(container, nameOnlyConfig))
+			assert.Equal(t, "foo", nameOnlyConfig.Name)
+
+			// WriteConfig uses the codec of the existing file.
+			require.NoError(t, WriteConfig(container, &testLayeredConfig{Name: "bar", Tags: []string{"baz"}}))
+			config = &testLayeredConfig{}
+			require.NoError(t, ReadConfig(container, config))
+			assert.Equal(t, &testLayeredConfig{Name: "bar", Tags: []string{"baz"}}, config)
+			entries, err := container.FileSystem().ReadDir("config")
+			require.NoError(t, err)
+			// The lock file is kept, and no temporary files are left.
+			require.Len(t, entries, 2)
+			assert.Equal(t, configLockFileName, entries[0].Name())
+			assert.Equal(t, testCase.fileName, entries[1].Name())
+		})
+	}
+}
+
+func TestReadConfigAmbiguous(t *testing.T) {
+	t.Parallel()
+	container := testNewCodecContainer(
+		t,
+		map[string]string{
+			"config.yaml": "name: foo\n",
+			"config.json": `{"name": "foo"}`,
+		},
+	)
+	err := ReadConfig(container, &testLayeredConfig{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "multiple foo-bar configuration files found")
+	assert.Error(t, WriteConfig(container, &testLayeredConfig{}))
+	// Restricting the codecs removes the ambiguity.
+	require.NoError(t, ReadConfig(container, &testLayeredConfig{}, ConfigWithCodecs(NewJSONConfigCodec())))
+}
+
+func TestReadConfigMissing(t *testing.T) {
+	t.Parallel()
+	container := testNewCodecContainer(t, nil)
+	config := &testLayeredConfig{Name: "default"}
+	require.NoError(t, ReadConfig(container, config))
+	assert.Equal(t, &testLayeredConfig{Name: "default"}, config)
+}
+
+func TestTOML(t *testing.T) {
+	t.Parallel()
+	codec := NewTOMLConfigCodec()
+	tree, err := codec.Unmarshal([]byte(`# comment
+title = "TOML \"example\"" # comment
+literal = 'C:\path'
+multi = """
+one \
+  two"""
+int = 1_000
+hex = 0xff
+float = 3.14
+exp = 1e3
+bool = true
+date = 1979-05-27T07:32:00Z
+local = 1979-05-27
+array = [
+  1,
+  2, # comment
+]
+inline = { a = 1, b.c = "d" }
+"quoted key" = 1
+dotted.key = "value"
+
+[server]
+host = "localhost"
+
+[server.tls]
+enabled = false
+
+[[plugins]]
+name = "one"
+
+[[plugins]]
+name = "two"
+`))
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		map[string]any{
+			"title":      `TOML "example"`,
+			"literal":    `C:\path`,
+			"multi":      "one two",
+			"int":        int64(1000),
+			"hex":        int64(255),
+			"float":      3.14,
+			"exp":        1000.0,
+			"bool":       true,
+			"date":       time.Date(1979, 5, 27, 7, 32, 0, 0, time.UTC),
+			"local":      "1979-05-27",
+			"array":      []any{int64(1), int64(2)},
+			"inline":     map[string]any{"a": int64(1), "b": map[string]any{"c": "d"}},
+			"quoted key": int64(1),
+			"dotted":     map[string]any{"key": "value"},
+			"server": map[string]any{
+				"host": "localhost",
+				"tls":  map[string]any{"enabled": false},
+			},
+			"plugins": []any{
+				map[string]any{"name": "one"},
+				map[string]any{"name": "two"},
+			},
+		},
+		tree,
+	)
+	data, err := codec.Marshal(tree)
+	require.NoError(t, err)
+	roundTripTree, err := codec.Unmarshal(data)
+	require.NoError(t, err)
+	assert.Equal(t, tree, roundTripTree)
+
+	for _, invalid := range []string{
+		"a = ",
+		"a = 1\na = 2",
+		"a = \"unterminated",
+		"[a\nb = 1",
+		"a = 1 b = 2",
+		"a = [1, 2",
+		"[t]\na = 1\n[t]\nb = 2",
+		"a = { x = 1 }\n[a]\ny = 2",
+	} {
+		_, err := codec.Unmarshal([]byte(invalid))
+		assert.Error(t, err, invalid)
+	}
+}
+
+func testNewCodecContainer(t *testing.T, fileNameToData map[string]string) NameContainer {
+	baseContainer := app.NewContainer(map[string]string{"FOO_BAR_CONFIG_DIR": "config"}, nil, nil, nil, "test")
+	fileSystem := baseContainer.FileSystem()
+	require.NoError(t, fileSystem.MkdirAll("config", 0755))
+	for fileName, data := range fileNameToData {
+		require.NoError(t, fileSystem.WriteFile(filepath.Join("config", fileName), []byte(data), 0644))
+	}
+	container, err := NewNameContainer(baseContainer, "foo-bar")
+	require.NoError(t, err)
+	return container
+}
+
+type testLayeredConfig struct {
+	Name     string           `yaml:"name"`
+	LogLevel string           `yaml:"log_level"`
+	Tags     []string         `yaml:"tags"`
+	Server   testServerConfig `yaml:"server"`
+}
+
+type testServerConfig struct {
+	Host string `yaml:"host"`
+	Port int    `yaml:"port"`
+}
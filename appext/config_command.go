@@ -0,0 +1,320 @@
+// Copyright 2025-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package appext
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"buf.build/go/app"
+	"buf.build/go/app/appcmd"
+)
+
+// configCommand implements the sub-commands of NewConfigCommand.
+type configCommand[T any] struct {
+	options []ConfigOption
+}
+
+func (c *configCommand[T]) get(_ context.Context, container Container) error {
+	key := container.Arg(0)
+	tree, err := c.readValueTree(container)
+	if err != nil {
+		return err
+	}
+	value, ok := getConfigTreeValue(tree, key)
+	if !ok {
+		return appcmd.NewInvalidArgumentErrorf("unknown configuration key %q", key)
+	}
+	formatted, err := formatConfigValue(value)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(container.Stdout(), formatted)
+	return err
+}
+
+func (c *configCommand[T]) list(_ context.Context, container Container) error {
+	tree, err := c.readValueTree(container)
+	if err != nil {
+		return err
+	}
+	for _, leafKey := range getConfigTreeLeafKeys(tree, "") {
+		value, _ := getConfigTreeValue(tree, leafKey)
+		formatted, err := formatConfigValue(value)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(container.Stdout(), "%s=%s\n", leafKey, formatted); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *configCommand[T]) set(ctx context.Context, container Container) error {
+	key := container.Arg(0)
+	keyType, err := getConfigCommandKeyType[T](key)
+	if err != nil {
+		return err
+	}
+	// The value is only parsed if the key is not a string, so that for example
+	// "0755" stays a string.
+	value := parseConfigString(container.Arg(1), keyType)
+	return c.updateFileTree(
+		ctx,
+		container,
+		func(tree configTree) error {
+			setConfigTreeValue(tree, c.getFileKey(container, key), value, ConfigSource{}, make(ConfigSources))
+			return nil
+		},
+	)
+}
+
+func (c *configCommand[T]) unset(ctx context.Context, container Container) error {
+	key := container.Arg(0)
+	if _, err := getConfigCommandKeyType[T](key); err != nil {
+		return err
+	}
+	return c.updateFileTree(
+		ctx,
+		container,
+		func(tree configTree) error {
+			deleteConfigTreeValue(tree, c.getFileKey(container, key))
+			return nil
+		},
+	)
+}
+
+func (c *configCommand[T]) path(_ context.Context, container Container) error {
+	configFilePath, _, err := c.getConfigFilePath(container)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(container.Stdout(), configFilePath)
+	return err
+}
+
+func (c *configCommand[T]) edit(ctx context.Context, container Container) error {
+	editor := container.Env("VISUAL")
+	if editor == "" {
+		editor = container.Env("EDITOR")
+	}
+	editorFields := strings.Fields(editor)
+	if len(editorFields) == 0 {
+		return errors.New("neither $VISUAL nor $EDITOR is set")
+	}
+	configFilePath, _, err := c.getConfigFilePath(container)
+	if err != nil {
+		return err
+	}
+	fileSystem := container.FileSystem()
+	if err := fileSystem.MkdirAll(filepath.Dir(configFilePath), 0755); err != nil {
+		return err
+	}
+	if _, err := fileSystem.Stat(configFilePath); errors.Is(err, os.ErrNotExist) {
+		if err := fileSystem.WriteFile(configFilePath, nil, 0644); err != nil {
+			return err
+		}
+	}
+	if err := app.Exec(ctx, container, editorFields[0], append(editorFields[1:], configFilePath)); err != nil {
+		return err
+	}
+	// Validate the edited file, leaving it in place so that the user can fix it.
+	return ReadConfig(container, new(T), c.options...)
+}
+
+// readValueTree reads the configuration into T and returns it as a tree, so that all
+// keys of T are present.
+func (c *configCommand[T]) readValueTree(container Container) (configTree, error) {
+	value := new(T)
+	if err := ReadConfig(container, value, c.options...); err != nil {
+		return nil, err
+	}
+	return valueToConfigTree(value)
+}
+
+// updateFileTree updates the tree of the configuration file in ConfigDirPath while
+// holding the configuration lock, validating the result against T before writing it.
+func (c *configCommand[T]) updateFileTree(ctx context.Context, container Container, update func(configTree) error) error {
+	configOptions := c.newConfigOptions(container)
+	return withConfigLock(
+		ctx,
+		container,
+		configOptions,
+		func() error {
+			configFilePath, codec, err := c.getConfigFilePath(container)
+			if err != nil {
+				return err
+			}
+			tree := make(configTree)
+			data, err := container.FileSystem().ReadFile(configFilePath)
+			if err != nil && !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+			if len(data) > 0 {
+				if tree, err = codec.Unmarshal(data); err != nil {
+					return fmt.Errorf("invalid %s configuration file at %s: %w", container.AppName(), configFilePath, err)
+				}
+				if configOptions.migrations != nil {
+					if _, err := configOptions.migrations.migrate(container.AppName(), configFilePath, tree); err != nil {
+						return err
+					}
+				}
+			}
+			if configOptions.migrations != nil {
+				// New files are written with the current version, so that they are not migrated.
+				tree[configVersionKey] = configOptions.migrations.currentVersion
+			}
+			if err := update(tree); err != nil {
+				return err
+			}
+			data, err = codec.Marshal(tree)
+			if err != nil {
+				return err
+			}
+			if err := validateConfigFileTree[T](container, tree, getConfigQuotedStrings(codec, data), configOptions); err != nil {
+				return appcmd.NewInvalidArgumentErrorf("invalid %s configuration: %v", container.AppName(), err)
+			}
+			return writeConfigFile(container, configFilePath, data)
+		},
+	)
+}
+
+// getFileKey returns the key within the configuration file for the key, which is within
+// the profiles section if a profile is selected.
+func (c *configCommand[T]) getFileKey(container Container, key string) string {
+	if profile := getConfigProfile(container, c.newConfigOptions(container)); profile != "" {
+		return joinConfigKey(joinConfigKey(configProfilesKey, profile), key)
+	}
+	return key
+}
+
+// getConfigFilePath returns the path and codec of the configuration file in ConfigDirPath,
+// or of the file that would be written if it does not exist.
+func (c *configCommand[T]) getConfigFilePath(container Container) (string, ConfigCodec, error) {
+	configOptions := c.newConfigOptions(container)
+	configFilePath, codec, err := findConfigFile(container, container.ConfigDirPath(), configFileBaseName, configOptions.codecs)
+	if err != nil {
+		return "", nil, err
+	}
+	if codec == nil {
+		codec = configOptions.codecs[0]
+		configFilePath = filepath.Join(container.ConfigDirPath(), configFileBaseName+codec.FileExtension())
+	}
+	return configFilePath, codec, nil
+}
+
+func (c *configCommand[T]) newConfigOptions(container Container) *configOptions {
+	configOptions := newConfigOptions(container.AppName())
+	for _, option := range c.options {
+		option(configOptions)
+	}
+	return configOptions
+}
+
+// validateConfigFileTree validates that the tree of a configuration file can be read into
+// T, for the base section and with each profile applied.
+//
+// The quoted strings are passed to interpolateConfigTree.
+func validateConfigFileTree[T any](container NameContainer, tree configTree, quoted map[string]struct{}, configOptions *configOptions) error {
+	profileNames, err := getConfigProfileNames(tree)
+	if err != nil {
+		return err
+	}
+	for _, profileName := range append([]string{""}, profileNames...) {
+		valueTree := normalizeConfigTree(tree)
+		if _, err := applyConfigProfile(valueTree, profileName); err != nil {
+			return err
+		}
+		if configOptions.interpolateEnv {
+			if err := interpolateConfigTree(container, valueTree, reflect.TypeFor[T](), quoted); err != nil {
+				return err
+			}
+		}
+		if err := unmarshalConfigTree(valueTree, new(T), true); err != nil {
+			if profileName != "" {
+				return fmt.Errorf("profile %q: %w", profileName, err)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// getConfigCommandKeyType validates that the dotted key addresses a value of T, and
+// returns the Go type of the value, which is nil if it is not known.
+func getConfigCommandKeyType[T any](key string) (reflect.Type, error) {
+	keyType, ok := getConfigKeyType(reflect.TypeFor[T](), key)
+	if !ok {
+		return nil, appcmd.NewInvalidArgumentErrorf("unknown configuration key %q", key)
+	}
+	return keyType, nil
+}
+
+// getConfigTreeValue returns the value at the dotted key.
+func getConfigTreeValue(tree configTree, key string) (any, bool) {
+	var value any = tree
+	for _, part := range strings.Split(key, ".") {
+		m, ok := value.(configTree)
+		if !ok {
+			return nil, false
+		}
+		if value, ok = m[part]; !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// deleteConfigTreeValue deletes the value at the dotted key, and removes any maps left empty.
+func deleteConfigTreeValue(tree configTree, key string) {
+	parent, rest, ok := strings.Cut(key, ".")
+	if !ok {
+		delete(tree, key)
+		return
+	}
+	child, ok := tree[parent].(configTree)
+	if !ok {
+		return
+	}
+	deleteConfigTreeValue(child, rest)
+	if len(child) == 0 {
+		delete(tree, parent)
+	}
+}
+
+// formatConfigValue formats the value for printing.
+//
+// Scalars are printed as is, and lists and maps are printed as JSON.
+func formatConfigValue(value any) (string, error) {
+	switch value.(type) {
+	case nil:
+		return "", nil
+	case []any, configTree:
+		data, err := json.Marshal(value)
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	default:
+		return fmt.Sprint(value), nil
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright 2025-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package appext
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"buf.build/go/app"
+	"buf.build/go/app/appcmd"
+	"buf.build/go/app/appcmd/appcmdtesting"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigCommand(t *testing.T) {
+	t.Parallel()
+	fileSystem := app.NewInMemoryFileSystem()
+	run := func(expectedExitCode int, expectedStdout string, args ...string) {
+		options := []appcmdtesting.RunOption{
+			appcmdtesting.WithFileSystem(fileSystem),
+			appcmdtesting.WithEnv(testNewConfigCommandEnv),
+			appcmdtesting.WithArgs(args...),
+			appcmdtesting.WithExpectedExitCode(expectedExitCode),
+		}
+		if expectedExitCode == 0 {
+			options = append(options, appcmdtesting.WithExpectedStdout(expectedStdout))
+		}
+		appcmdtesting.Run(t, testNewConfigCommand, options...)
+	}
+
+	run(0, "config/config.yaml", "path")
+	run(0, "", "set", "server.port", "8080")
+	run(0, "", "set", "server.host", "123")
+	run(0, "", "set", "tags", "[a, b]")
+	run(0, "8080", "get", "server.port")
+	run(0, "123", "get", "server.host")
+	run(0, `["a","b"]`, "get", "tags")
+	run(
+		0,
+		`log_level=
+name=
+server.host=123
+server.port=8080
+tags=["a","b"]`,
+		"list",
+	)
+	run(1, "", "set", "server.port", "abc")
+	run(1, "", "set", "server.unknown", "abc")
+	run(1, "", "get", "unknown")
+	run(0, "", "unset", "server.port")
+	run(0, "", "unset", "server.host")
+	data, err := fileSystem.ReadFile(filepath.Join("config", "config.yaml"))
+	require.NoError(t, err)
+	require.Equal(t, "tags:\n  - a\n  - b\n", string(data))
+}
+
+func TestConfigCommandSetTypes(t *testing.T) {
+	t.Parallel()
+	fileSystem := app.NewInMemoryFileSystem()
+	run := func(args ...string) {
+		appcmdtesting.Run(
+			t,
+			func(use string) *appcmd.Command {
+				return NewConfigCommand[testPointerConfig](use, NewBuilder("foo-bar"))
+			},
+			appcmdtesting.WithFileSystem(fileSystem),
+			appcmdtesting.WithEnv(testNewConfigCommandEnv),
+			appcmdtesting.WithArgs(args...),
+		)
+	}
+	// The types are known from T even if the zero value of T does not have the keys.
+	run("set", "server.host", "0755")
+	run("set", "labels.mode", "0755")
+	run("set", "modes", "[0755, 1]")
+	run("set", "server.port", "0x1F")
+	container := app.NewContainerForFileSystem(app.NewContainer(testNewConfigCommandEnv(""), nil, nil, nil), fileSystem)
+	nameContainer, err := NewNameContainer(container, "foo-bar")
+	require.NoError(t, err)
+	config := &testPointerConfig{}
+	require.NoError(t, ReadConfig(nameContainer, config))
+	require.Equal(
+		t,
+		&testPointerConfig{
+			Server: &testServerConfig{Host: "0755", Port: 31},
+			Labels: map[string]string{"mode": "0755"},
+			Modes:  []string{"0755", "1"},
+		},
+		config,
+	)
+}
+
+func TestConfigCommandEdit(t *testing.T) {
+	t.Parallel()
+	fileSystem := app.NewInMemoryFileSystem()
+	runner := app.NewFakeRunner(
+		func(_ context.Context, command *app.ExecCommand) error {
+			return fileSystem.WriteFile(command.Args[len(command.Args)-1], []byte("name: edited\n"), 0644)
+		},
+	)
+	appcmdtesting.Run(
+		t,
+		testNewConfigCommand,
+		appcmdtesting.WithFileSystem(fileSystem),
+		appcmdtesting.WithRunner(runner),
+		appcmdtesting.WithEnv(
+			func(use string) map[string]string {
+				env := testNewConfigCommandEnv(use)
+				env["EDITOR"] = "vi"
+				env["VISUAL"] = "code --wait"
+				return env
+			},
+		),
+		appcmdtesting.WithArgs("edit"),
+	)
+	commands := runner.Commands()
+	require.Len(t, commands, 1)
+	require.Equal(t, "code", commands[0].Name)
+	require.Equal(t, []string{"--wait", filepath.Join("config", "config.yaml")}, commands[0].Args)
+	data, err := fileSystem.ReadFile(filepath.Join("config", "config.yaml"))
+	require.NoError(t, err)
+	require.Equal(t, "name: edited\n", string(data))
+}
+
+func testNewConfigCommand(use string) *appcmd.Command {
+	return NewConfigCommand[testLayeredConfig](use, NewBuilder("foo-bar"))
+}
+
+type testPointerConfig struct {
+	Server *testServerConfig `yaml:"server,omitempty"`
+	Labels map[string]string `yaml:"labels,omitempty"`
+	Modes  []string          `yaml:"modes,omitempty"`
+}
+
+func testNewConfigCommandEnv(string) map[string]string {
+	return map[string]string{
+		"FOO_BAR_CONFIG_DIR": "config",
+	}
+}
@@ -0,0 +1,226 @@
+// Copyright 2025-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package appext
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+
+	"buf.build/go/app"
+	"gopkg.in/yaml.v3"
+)
+
+// interpolateConfigTree expands environment variable references in all string values
+// of the tree in place.
+//
+// Supported forms are ${VAR} and ${VAR:-default}, where the default is used if VAR is
+// not set or empty. $$ is a literal $, and a $ not followed by { or $ is left as is.
+//
+// Expanded values are kept as strings, and are only resolved as YAML scalars if the
+// value is decoded into a field of typ that is not a string, and the string was not
+// quoted. The quoted strings are the strings in quoted, see getConfigQuotedStrings.
+//
+// Returns an error listing all undefined variables if any variable without a default
+// is not set.
+func interpolateConfigTree(
+	envContainer app.EnvContainer,
+	tree configTree,
+	typ reflect.Type,
+	quoted map[string]struct{},
+) error {
+	interpolator := &configInterpolator{
+		envContainer: envContainer,
+		quoted:       quoted,
+		undefined:    make(map[string]struct{}),
+	}
+	if err := interpolator.interpolateTree(tree, typ); err != nil {
+		return err
+	}
+	if len(interpolator.undefined) > 0 {
+		undefined := make([]string, 0, len(interpolator.undefined))
+		for name := range interpolator.undefined {
+			undefined = append(undefined, name)
+		}
+		sort.Strings(undefined)
+		return fmt.Errorf("undefined environment variables: %s", strings.Join(undefined, ", "))
+	}
+	return nil
+}
+
+type configInterpolator struct {
+	envContainer app.EnvContainer
+	quoted       map[string]struct{}
+	undefined    map[string]struct{}
+}
+
+// interpolateTree interpolates the tree, which is decoded into a value of the type.
+//
+// The type is nil if it is not known.
+func (c *configInterpolator) interpolateTree(tree configTree, typ reflect.Type) error {
+	for key, value := range tree {
+		var childType reflect.Type
+		if typ != nil {
+			childType, _ = getConfigChildType(typ, key)
+		}
+		interpolated, err := c.interpolateValue(value, childType)
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		tree[key] = interpolated
+	}
+	return nil
+}
+
+func (c *configInterpolator) interpolateValue(value any, typ reflect.Type) (any, error) {
+	switch t := value.(type) {
+	case configTree:
+		return t, c.interpolateTree(t, typ)
+	case []any:
+		elemType := getConfigElemType(typ)
+		for i, elem := range t {
+			interpolated, err := c.interpolateValue(elem, elemType)
+			if err != nil {
+				return nil, fmt.Errorf("[%d]: %w", i, err)
+			}
+			t[i] = interpolated
+		}
+		return t, nil
+	case string:
+		return c.interpolateString(t, typ)
+	default:
+		return value, nil
+	}
+}
+
+// interpolateString expands the references in the string, which is decoded into a value
+// of the type.
+//
+// If the string contained a reference and was not quoted, and the type is known and not
+// a string, the result is resolved as a YAML scalar, so that for example ${PORT} can be
+// decoded into an int, while "${MODE}" with MODE=0755 stays the string "0755".
+func (c *configInterpolator) interpolateString(s string, typ reflect.Type) (any, error) {
+	if !strings.Contains(s, "$") {
+		return s, nil
+	}
+	var builder strings.Builder
+	var expanded bool
+	for i := 0; i < len(s); i++ {
+		if s[i] != '$' || i+1 == len(s) {
+			builder.WriteByte(s[i])
+			continue
+		}
+		switch s[i+1] {
+		case '$':
+			builder.WriteByte('$')
+			i++
+		case '{':
+			end := strings.IndexByte(s[i:], '}')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated variable reference in %q", s)
+			}
+			name, defaultValue, hasDefault := strings.Cut(s[i+2:i+end], ":-")
+			if !isConfigEnvKey(name) {
+				return nil, fmt.Errorf("invalid variable reference in %q", s)
+			}
+			value := c.envContainer.Env(name)
+			if value == "" {
+				if hasDefault {
+					value = defaultValue
+				} else {
+					c.undefined[name] = struct{}{}
+				}
+			}
+			builder.WriteString(value)
+			expanded = true
+			i += end
+		default:
+			builder.WriteByte('$')
+		}
+	}
+	if _, ok := c.quoted[s]; !expanded || ok || typ == nil || isConfigStringType(typ) {
+		return builder.String(), nil
+	}
+	return resolveConfigScalar(builder.String()), nil
+}
+
+// getConfigQuotedStrings returns the strings with references that are only written as
+// quoted or block scalars in the data, which are strings regardless of their content.
+//
+// Returns nil if the codec is not YAML, as only YAML has plain scalars, or if the data
+// is not valid YAML.
+func getConfigQuotedStrings(codec ConfigCodec, data []byte) map[string]struct{} {
+	if _, ok := codec.(yamlConfigCodec); !ok {
+		return nil
+	}
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil
+	}
+	quoted := make(map[string]struct{})
+	plain := make(map[string]struct{})
+	var walk func(*yaml.Node)
+	walk = func(node *yaml.Node) {
+		if node.Kind == yaml.ScalarNode && node.ShortTag() == "!!str" && strings.Contains(node.Value, "$") {
+			if node.Style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle|yaml.LiteralStyle|yaml.FoldedStyle) != 0 {
+				quoted[node.Value] = struct{}{}
+			} else {
+				plain[node.Value] = struct{}{}
+			}
+		}
+		for _, child := range node.Content {
+			walk(child)
+		}
+	}
+	walk(&node)
+	// Strings that are also written as plain scalars cannot be told apart.
+	for value := range plain {
+		delete(quoted, value)
+	}
+	return quoted
+}
+
+// resolveConfigScalar resolves the string as a YAML scalar, falling back to the string itself.
+func resolveConfigScalar(s string) any {
+	var node yaml.Node
+	if err := yaml.Unmarshal([]byte(s), &node); err != nil || len(node.Content) != 1 {
+		return s
+	}
+	scalarNode := node.Content[0]
+	if scalarNode.Kind != yaml.ScalarNode || scalarNode.Style != 0 ||
+		!slices.Contains([]string{"!!bool", "!!int", "!!float"}, scalarNode.ShortTag()) {
+		return s
+	}
+	var value any
+	if err := scalarNode.Decode(&value); err != nil {
+		return s
+	}
+	return value
+}
+
+func isConfigEnvKey(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := range len(s) {
+		c := s[i]
+		if !((c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c == '_' || (i > 0 && c >= '0' && c <= '9')) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,158 @@
+// Copyright 2025-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package appext
+
+import (
+	"path/filepath"
+	"testing"
+
+	"buf.build/go/app"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadConfigEnvInterpolation(t *testing.T) {
+	t.Parallel()
+	container := testNewInterpolationContainer(
+		t,
+		`name: ${NAME}
+log_level: ${LOG_LEVEL:-info}
+tags:
+  - ${HOME}/certs
+  - $$HOME
+  - cost$5
+server:
+  host: ${HOST:-localhost}
+  port: ${PORT}
+`,
+		map[string]string{
+			"NAME": "foo",
+			"HOME": "/home/foo",
+			"PORT": "8080",
+		},
+	)
+	var config testLayeredConfig
+	require.NoError(t, ReadConfig(container, &config, ConfigWithEnvInterpolation()))
+	require.Equal(
+		t,
+		testLayeredConfig{
+			Name:     "foo",
+			LogLevel: "info",
+			Tags:     []string{"/home/foo/certs", "$HOME", "cost$5"},
+			Server: testServerConfig{
+				Host: "localhost",
+				Port: 8080,
+			},
+		},
+		config,
+	)
+
+	var loadedConfig testLayeredConfig
+	_, err := LoadConfig(container, &loadedConfig, ConfigWithEnvInterpolation())
+	require.NoError(t, err)
+	require.Equal(t, config, loadedConfig)
+
+	// Without the option, references are not expanded.
+	require.Error(t, ReadConfig(container, &testLayeredConfig{}))
+}
+
+func TestReadConfigEnvInterpolationTypes(t *testing.T) {
+	t.Parallel()
+	env := map[string]string{
+		"NAME": "0x1F",
+		"MODE": "0755",
+		"PORT": "0x1F",
+		"TAG":  "true",
+	}
+	container := testNewInterpolationContainer(
+		t,
+		`name: ${NAME}
+log_level: "${MODE}"
+tags:
+  - ${TAG}
+server:
+  host: '${MODE}'
+  port: ${PORT}
+`,
+		env,
+	)
+	var config testLayeredConfig
+	require.NoError(t, ReadConfig(container, &config, ConfigWithEnvInterpolation()))
+	require.Equal(
+		t,
+		testLayeredConfig{
+			Name:     "0x1F",
+			LogLevel: "0755",
+			Tags:     []string{"true"},
+			Server: testServerConfig{
+				Host: "0755",
+				Port: 31,
+			},
+		},
+		config,
+	)
+	// Quoted values are strings, so they cannot be decoded into an int.
+	container = testNewInterpolationContainer(t, "server:\n  port: \"${PORT}\"\n", env)
+	require.Error(t, ReadConfig(container, &testLayeredConfig{}, ConfigWithEnvInterpolation()))
+	// Values within interfaces are kept as strings.
+	var mapConfig map[string]any
+	_, err := LoadConfig(container, &mapConfig, ConfigWithEnvInterpolation())
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"server": map[string]any{"port": "0x1F"}}, mapConfig)
+}
+
+func TestReadConfigEnvInterpolationUndefined(t *testing.T) {
+	t.Parallel()
+	container := testNewInterpolationContainer(
+		t,
+		`name: ${NAME}
+log_level: ${LOG_LEVEL}
+server:
+  host: ${HOST}
+  port: ${PORT:-8080}
+`,
+		nil,
+	)
+	var config testLayeredConfig
+	err := ReadConfig(container, &config, ConfigWithEnvInterpolation())
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "undefined environment variables: HOST, LOG_LEVEL, NAME")
+}
+
+func TestReadConfigEnvInterpolationInvalid(t *testing.T) {
+	t.Parallel()
+	for _, data := range []string{
+		"name: ${NAME\n",
+		"name: ${}\n",
+		"name: ${1NAME}\n",
+	} {
+		container := testNewInterpolationContainer(t, data, nil)
+		var config testLayeredConfig
+		require.Error(t, ReadConfig(container, &config, ConfigWithEnvInterpolation()), data)
+	}
+}
+
+func testNewInterpolationContainer(t *testing.T, data string, env map[string]string) NameContainer {
+	allEnv := map[string]string{"FOO_BAR_CONFIG_DIR": "config"}
+	for key, value := range env {
+		allEnv[key] = value
+	}
+	baseContainer := app.NewContainer(allEnv, nil, nil, nil, "test")
+	fileSystem := baseContainer.FileSystem()
+	require.NoError(t, fileSystem.MkdirAll("config", 0755))
+	require.NoError(t, fileSystem.WriteFile(filepath.Join("config", "config.yaml"), []byte(data), 0644))
+	container, err := NewNameContainer(baseContainer, "foo-bar")
+	require.NoError(t, err)
+	return container
+}
@@ -0,0 +1,59 @@
+// Copyright 2025-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package appext
+
+import (
+	"strconv"
+)
+
+const (
+	// ConfigLayerDefault is the value passed to LoadConfig before loading.
+	ConfigLayerDefault ConfigLayer = iota + 1
+	// ConfigLayerSystem is the system-wide configuration files in ConfigDirPaths.
+	ConfigLayerSystem
+	// ConfigLayerUser is the user configuration file in ConfigDirPath.
+	ConfigLayerUser
+	// ConfigLayerProject is the project configuration file found by walking up from the working directory.
+	ConfigLayerProject
+	// ConfigLayerEnv is the APP_NAME_ environment variables.
+	ConfigLayerEnv
+	// ConfigLayerFlag is the flags that were explicitly set.
+	ConfigLayerFlag
+)
+
+// ConfigLayer is a layer of configuration for LoadConfig.
+//
+// Later layers take precedence over earlier layers.
+type ConfigLayer int
+
+// String implements fmt.Stringer.
+func (c ConfigLayer) String() string {
+	switch c {
+	case ConfigLayerDefault:
+		return "default"
+	case ConfigLayerSystem:
+		return "system"
+	case ConfigLayerUser:
+		return "user"
+	case ConfigLayerProject:
+		return "project"
+	case ConfigLayerEnv:
+		return "env"
+	case ConfigLayerFlag:
+		return "flag"
+	default:
+		return strconv.Itoa(int(c))
+	}
+}
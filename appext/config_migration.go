@@ -0,0 +1,102 @@
+// Copyright 2025-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package appext
+
+import (
+	"fmt"
+	"math"
+)
+
+const configVersionKey = "version"
+
+type configMigrations struct {
+	currentVersion int
+	migrations     map[int]func(map[string]any) error
+}
+
+// migrate migrates the tree to the current version in place.
+//
+// Returns true if the tree was migrated.
+func (c *configMigrations) migrate(appName string, configFilePath string, tree map[string]any) (bool, error) {
+	version, err := getConfigTreeVersion(tree)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s configuration file at %s: %w", appName, configFilePath, err)
+	}
+	if version > c.currentVersion {
+		return false, fmt.Errorf(
+			"%s configuration file at %s has version %d, but this version of %s only supports versions up to %d, upgrade %s to read it",
+			appName,
+			configFilePath,
+			version,
+			appName,
+			c.currentVersion,
+			appName,
+		)
+	}
+	if version == c.currentVersion {
+		return false, nil
+	}
+	for ; version < c.currentVersion; version++ {
+		migration, ok := c.migrations[version]
+		if !ok {
+			return false, fmt.Errorf(
+				"%s configuration file at %s has version %d, but there is no migration from version %d to version %d",
+				appName,
+				configFilePath,
+				version,
+				version,
+				version+1,
+			)
+		}
+		if err := migration(tree); err != nil {
+			return false, fmt.Errorf(
+				"could not migrate %s configuration file at %s from version %d to version %d: %w",
+				appName,
+				configFilePath,
+				version,
+				version+1,
+				err,
+			)
+		}
+	}
+	tree[configVersionKey] = c.currentVersion
+	return true, nil
+}
+
+// getConfigTreeVersion returns the version of the tree, or 0 if the tree has no version.
+func getConfigTreeVersion(tree map[string]any) (int, error) {
+	value, ok := tree[configVersionKey]
+	if !ok || value == nil {
+		return 0, nil
+	}
+	var version int64
+	switch typedValue := value.(type) {
+	case int:
+		version = int64(typedValue)
+	case int64:
+		version = typedValue
+	case uint64:
+		if typedValue > math.MaxInt32 {
+			return 0, fmt.Errorf("%s %d is out of range", configVersionKey, typedValue)
+		}
+		version = int64(typedValue)
+	default:
+		return 0, fmt.Errorf("%s must be an integer", configVersionKey)
+	}
+	if version < 0 || version > math.MaxInt32 {
+		return 0, fmt.Errorf("%s %d is out of range", configVersionKey, version)
+	}
+	return int(version), nil
+}
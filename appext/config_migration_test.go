@@ -0,0 +1,162 @@
+// Copyright 2025-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package appext
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadConfigMigrations(t *testing.T) {
+	t.Parallel()
+	container := testNewCodecContainer(t, map[string]string{"config.yaml": "addr: localhost\n"})
+	config := &testVersionedConfig{}
+	require.NoError(t, ReadConfig(container, config, testConfigWithMigrations()))
+	assert.Equal(t, &testVersionedConfig{Version: 2, Server: testServerConfig{Host: "localhost"}}, config)
+	// Without ConfigWithWriteMigrated, the file is not changed.
+	data, err := container.FileSystem().ReadFile(filepath.Join("config", "config.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "addr: localhost\n", string(data))
+
+	config = &testVersionedConfig{}
+	require.NoError(t, ReadConfig(container, config, testConfigWithMigrations(), ConfigWithWriteMigrated()))
+	assert.Equal(t, &testVersionedConfig{Version: 2, Server: testServerConfig{Host: "localhost"}}, config)
+	data, err = container.FileSystem().ReadFile(filepath.Join("config", "config.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "server:\n  host: localhost\nversion: 2\n", string(data))
+}
+
+func TestReadConfigMigrationsErrors(t *testing.T) {
+	t.Parallel()
+	container := testNewCodecContainer(t, map[string]string{"config.yaml": "version: 3\n"})
+	err := ReadConfig(container, &testVersionedConfig{}, testConfigWithMigrations())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "has version 3, but this version of foo-bar only supports versions up to 2")
+
+	container = testNewCodecContainer(t, map[string]string{"config.yaml": "version: 1\n"})
+	err = ReadConfig(
+		container,
+		&testVersionedConfig{},
+		ConfigWithMigrations(
+			2,
+			map[int]func(map[string]any) error{
+				1: func(map[string]any) error {
+					return errors.New("boom")
+				},
+			},
+		),
+	)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "from version 1 to version 2: boom")
+
+	container = testNewCodecContainer(t, map[string]string{"config.yaml": "version: 1\n"})
+	err = ReadConfig(container, &testVersionedConfig{}, ConfigWithMigrations(2, nil))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no migration from version 1 to version 2")
+
+	container = testNewCodecContainer(t, map[string]string{"config.yaml": "version: two\n"})
+	err = ReadConfig(container, &testVersionedConfig{}, testConfigWithMigrations())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "version must be an integer")
+}
+
+func TestLoadConfigMigrations(t *testing.T) {
+	t.Parallel()
+	container := testNewCodecContainer(t, map[string]string{"config.json": `{"version": 1, "host": "localhost"}`})
+	config := &testVersionedConfig{}
+	_, err := LoadConfig(container, config, testConfigWithMigrations(), ConfigWithWriteMigrated())
+	require.NoError(t, err)
+	assert.Equal(t, &testVersionedConfig{Version: 2, Server: testServerConfig{Host: "localhost"}}, config)
+	config = &testVersionedConfig{}
+	require.NoError(t, ReadConfig(container, config))
+	assert.Equal(t, &testVersionedConfig{Version: 2, Server: testServerConfig{Host: "localhost"}}, config)
+}
+
+func TestWriteMigratedConfigRereads(t *testing.T) {
+	t.Parallel()
+	container := testNewCodecContainer(t, map[string]string{"config.yaml": "addr: localhost\n"})
+	configOptions := newConfigOptions(container.AppName())
+	testConfigWithMigrations()(configOptions)
+	configFilePath := filepath.Join("config", "config.yaml")
+	// The file is changed by another writer after it was read and migrated.
+	require.NoError(t, container.FileSystem().WriteFile(configFilePath, []byte("addr: example.com\n"), 0644))
+	require.NoError(t, writeMigratedConfig(container, configFilePath, NewYAMLConfigCodec(), configOptions))
+	data, err := container.FileSystem().ReadFile(configFilePath)
+	require.NoError(t, err)
+	assert.Equal(t, "server:\n  host: example.com\nversion: 2\n", string(data))
+
+	// The file is not written back while the lock is held.
+	require.NoError(t, container.FileSystem().WriteFile(configFilePath, []byte("addr: localhost\n"), 0644))
+	unlock, err := acquireFileLock(t.Context(), container, filepath.Join("config", configLockFileName), &lockOptions{})
+	require.NoError(t, err)
+	require.NoError(t, writeMigratedConfig(container, configFilePath, NewYAMLConfigCodec(), configOptions))
+	require.NoError(t, unlock())
+	data, err = container.FileSystem().ReadFile(configFilePath)
+	require.NoError(t, err)
+	assert.Equal(t, "addr: localhost\n", string(data))
+}
+
+func TestWriteConfigVersion(t *testing.T) {
+	t.Parallel()
+	container := testNewCodecContainer(t, nil)
+	// The version is set even if the value does not set it.
+	require.NoError(t, WriteConfig(container, &testVersionedConfig{Server: testServerConfig{Host: "localhost"}}, testConfigWithMigrations()))
+	config := &testVersionedConfig{}
+	require.NoError(t, ReadConfig(container, config))
+	assert.Equal(t, &testVersionedConfig{Version: 2, Server: testServerConfig{Host: "localhost"}}, config)
+	require.NoError(
+		t,
+		UpdateConfig(
+			container,
+			func(config *testVersionedConfig) error {
+				config.Server.Host = "example.com"
+				return nil
+			},
+			testConfigWithMigrations(),
+		),
+	)
+	config = &testVersionedConfig{}
+	require.NoError(t, ReadConfig(container, config))
+	assert.Equal(t, &testVersionedConfig{Version: 2, Server: testServerConfig{Host: "example.com"}}, config)
+}
+
+// testConfigWithMigrations migrates from version 0, where the host was named addr, to
+// version 1, where it was named host, to version 2, where it moved under server.
+func testConfigWithMigrations() ConfigOption {
+	return ConfigWithMigrations(
+		2,
+		map[int]func(map[string]any) error{
+			0: func(tree map[string]any) error {
+				tree["host"] = tree["addr"]
+				delete(tree, "addr")
+				return nil
+			},
+			1: func(tree map[string]any) error {
+				tree["server"] = map[string]any{"host": tree["host"]}
+				delete(tree, "host")
+				return nil
+			},
+		},
+	)
+}
+
+type testVersionedConfig struct {
+	Version int              `yaml:"version"`
+	Server  testServerConfig `yaml:"server"`
+}
@@ -0,0 +1,83 @@
+// Copyright 2025-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package appext
+
+import (
+	"fmt"
+	"sort"
+)
+
+// applyConfigProfile removes the profiles section from the tree, and merges the profile
+// over the remaining base section if the profile is not empty.
+//
+// The tree is modified in place. Returns true if the profile was found.
+func applyConfigProfile(tree configTree, profile string) (bool, error) {
+	profilesTree, err := getConfigProfilesTree(tree)
+	if err != nil {
+		return false, err
+	}
+	delete(tree, configProfilesKey)
+	if profile == "" {
+		return false, nil
+	}
+	profileValue, ok := profilesTree[profile]
+	if !ok {
+		return false, nil
+	}
+	switch t := profileValue.(type) {
+	case nil:
+	case configTree:
+		mergeConfigTree(tree, t, "", ConfigSource{}, make(ConfigSources))
+	default:
+		return false, fmt.Errorf("%s.%s must be a map", configProfilesKey, profile)
+	}
+	return true, nil
+}
+
+// getConfigProfileNames returns the sorted names of the profiles in the tree.
+func getConfigProfileNames(tree configTree) ([]string, error) {
+	profilesTree, err := getConfigProfilesTree(tree)
+	if err != nil {
+		return nil, err
+	}
+	profileNames := make([]string, 0, len(profilesTree))
+	for profileName := range profilesTree {
+		profileNames = append(profileNames, profileName)
+	}
+	sort.Strings(profileNames)
+	return profileNames, nil
+}
+
+// getConfigProfilesTree returns the profiles section of the tree, or nil if there is none.
+func getConfigProfilesTree(tree configTree) (configTree, error) {
+	switch t := tree[configProfilesKey].(type) {
+	case nil:
+		return nil, nil
+	case configTree:
+		return t, nil
+	default:
+		return nil, fmt.Errorf("%s must be a map of profile names to configuration", configProfilesKey)
+	}
+}
+
+// getConfigProfile returns the profile to use.
+//
+// This is the profile given with ConfigWithProfile, or NameContainer.Profile otherwise.
+func getConfigProfile(container NameContainer, configOptions *configOptions) string {
+	if configOptions.profile != nil {
+		return *configOptions.profile
+	}
+	return container.Profile()
+}
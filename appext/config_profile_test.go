@@ -0,0 +1,138 @@
+// Copyright 2025-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package appext
+
+import (
+	"path/filepath"
+	"testing"
+
+	"buf.build/go/app"
+	"buf.build/go/app/appcmd/appcmdtesting"
+	"github.com/stretchr/testify/require"
+)
+
+const testProfileConfigData = `name: foo
+server:
+  host: localhost
+  port: 8080
+profiles:
+  production:
+    server:
+      host: example.com
+  staging:
+    name: foo-staging
+`
+
+func TestReadConfigProfile(t *testing.T) {
+	t.Parallel()
+	container := testNewCodecContainer(t, map[string]string{"config.yaml": testProfileConfigData})
+	var config testLayeredConfig
+	require.NoError(t, ReadConfig(container, &config))
+	require.Equal(t, testLayeredConfig{Name: "foo", Server: testServerConfig{Host: "localhost", Port: 8080}}, config)
+	config = testLayeredConfig{}
+	require.NoError(t, ReadConfig(container, &config, ConfigWithProfile("production")))
+	require.Equal(t, testLayeredConfig{Name: "foo", Server: testServerConfig{Host: "example.com", Port: 8080}}, config)
+	err := ReadConfig(container, &testLayeredConfig{}, ConfigWithProfile("unknown"))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `profile "unknown" not found`)
+
+	// The profile is selected by the environment.
+	container, err = NewNameContainer(app.NewContainerForEnv(container, app.NewEnvContainerWithOverrides(container, map[string]string{"FOO_BAR_PROFILE": "staging"})), "foo-bar")
+	require.NoError(t, err)
+	require.Equal(t, "staging", container.Profile())
+	config = testLayeredConfig{}
+	require.NoError(t, ReadConfig(container, &config))
+	require.Equal(t, testLayeredConfig{Name: "foo-staging", Server: testServerConfig{Host: "localhost", Port: 8080}}, config)
+	var loadedConfig testLayeredConfig
+	_, err = LoadConfig(container, &loadedConfig)
+	require.NoError(t, err)
+	require.Equal(t, config.Name, loadedConfig.Name)
+	require.Equal(t, config.Server, loadedConfig.Server)
+	// ConfigWithProfile overrides the environment.
+	config = testLayeredConfig{}
+	require.NoError(t, ReadConfig(container, &config, ConfigWithProfile("")))
+	require.Equal(t, "foo", config.Name)
+	_, err = LoadConfig(container, &testLayeredConfig{}, ConfigWithProfile("unknown"))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `profile "unknown" not found in any foo-bar configuration file`)
+}
+
+func TestWriteConfigPreservesProfiles(t *testing.T) {
+	t.Parallel()
+	container := testNewCodecContainer(t, map[string]string{"config.yaml": testProfileConfigData})
+	require.NoError(
+		t,
+		UpdateConfig(
+			container,
+			func(config *testLayeredConfig) error {
+				require.Equal(t, "localhost", config.Server.Host)
+				config.Server.Port = 9090
+				return nil
+			},
+			ConfigWithProfile("production"),
+		),
+	)
+	var config testLayeredConfig
+	require.NoError(t, ReadConfig(container, &config, ConfigWithProfile("production")))
+	require.Equal(t, "foo", config.Name)
+	require.Equal(t, testServerConfig{Host: "example.com", Port: 9090}, config.Server)
+}
+
+func TestConfigCommandProfile(t *testing.T) {
+	t.Parallel()
+	fileSystem := app.NewInMemoryFileSystem()
+	require.NoError(t, fileSystem.MkdirAll("config", 0755))
+	require.NoError(t, fileSystem.WriteFile(filepath.Join("config", "config.yaml"), []byte(testProfileConfigData), 0644))
+	run := func(profile string, expectedStdout string, args ...string) {
+		appcmdtesting.Run(
+			t,
+			testNewConfigCommand,
+			appcmdtesting.WithFileSystem(fileSystem),
+			appcmdtesting.WithEnv(
+				func(use string) map[string]string {
+					env := testNewConfigCommandEnv(use)
+					env["FOO_BAR_PROFILE"] = profile
+					return env
+				},
+			),
+			appcmdtesting.WithArgs(args...),
+			appcmdtesting.WithExpectedStdout(expectedStdout),
+		)
+	}
+	run("staging", "", "set", "server.port", "9090")
+	run("staging", "9090", "get", "server.port")
+	run("production", "8080", "get", "server.port")
+	run("", "8080", "get", "server.port")
+	run("staging", "", "unset", "name")
+	run("staging", "foo", "get", "name")
+	data, err := fileSystem.ReadFile(filepath.Join("config", "config.yaml"))
+	require.NoError(t, err)
+	require.Equal(
+		t,
+		`name: foo
+profiles:
+  production:
+    server:
+      host: example.com
+  staging:
+    server:
+      port: 9090
+server:
+  host: localhost
+  port: 8080
+`,
+		string(data),
+	)
+}
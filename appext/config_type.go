@@ -0,0 +1,206 @@
+// Copyright 2025-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package appext
+
+import (
+	"encoding"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	yamlUnmarshalerType = reflect.TypeFor[yaml.Unmarshaler]()
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
+// getConfigKeyType returns the Go type that the value at the dotted key is decoded into,
+// for values of the type.
+//
+// Struct fields are matched by their YAML names. Returns nil and true if the key is valid
+// but its type is not known, for example if the key is within an interface. Returns false
+// if the key does not address a value of the type.
+func getConfigKeyType(typ reflect.Type, key string) (reflect.Type, bool) {
+	for part := range strings.SplitSeq(key, ".") {
+		if typ == nil {
+			return nil, true
+		}
+		var ok bool
+		if typ, ok = getConfigChildType(typ, part); !ok {
+			return nil, false
+		}
+	}
+	return typ, true
+}
+
+// getConfigChildType returns the Go type of the value with the key within values of the type.
+//
+// Returns nil and true if the type of the value is not known.
+func getConfigChildType(typ reflect.Type, key string) (reflect.Type, bool) {
+	typ = indirectConfigType(typ)
+	if typ == nil {
+		return nil, true
+	}
+	switch typ.Kind() {
+	case reflect.Struct:
+		return getConfigFieldType(typ, key)
+	case reflect.Map:
+		return typ.Elem(), true
+	case reflect.Interface:
+		return nil, true
+	default:
+		return nil, false
+	}
+}
+
+// getConfigTypeLeafKeys returns the sorted dotted keys of the leaf values of the type.
+//
+// Struct fields are leaves unless they are structs, which are descended into, so that
+// fields with nil or omitted default values have keys. Map fields are not leaves, as their
+// keys are not known from the type, and types that decode themselves from YAML or text are
+// leaves. Recursive types are only descended into once.
+func getConfigTypeLeafKeys(typ reflect.Type) []string {
+	leafKeys := appendConfigTypeLeafKeys(nil, typ, "", make(map[reflect.Type]struct{}))
+	sort.Strings(leafKeys)
+	return leafKeys
+}
+
+func appendConfigTypeLeafKeys(leafKeys []string, typ reflect.Type, prefix string, visiting map[reflect.Type]struct{}) []string {
+	typ = indirectConfigType(typ)
+	if typ == nil || typ.Kind() != reflect.Struct {
+		return leafKeys
+	}
+	if _, ok := visiting[typ]; ok {
+		return leafKeys
+	}
+	visiting[typ] = struct{}{}
+	defer delete(visiting, typ)
+	for i := range typ.NumField() {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		tagName, tagFlags, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if tagName == "-" {
+			continue
+		}
+		fieldType := indirectConfigType(field.Type)
+		if slices.Contains(strings.Split(tagFlags, ","), "inline") {
+			leafKeys = appendConfigTypeLeafKeys(leafKeys, fieldType, prefix, visiting)
+			continue
+		}
+		if tagName == "" {
+			tagName = strings.ToLower(field.Name)
+		}
+		key := joinConfigKey(prefix, tagName)
+		switch {
+		case fieldType.Kind() == reflect.Map:
+		case fieldType.Kind() == reflect.Struct && !isConfigUnmarshalerType(fieldType):
+			leafKeys = appendConfigTypeLeafKeys(leafKeys, fieldType, key, visiting)
+		default:
+			leafKeys = append(leafKeys, key)
+		}
+	}
+	return leafKeys
+}
+
+// isConfigUnmarshalerType returns true if pointers to the type decode themselves from YAML
+// or text.
+func isConfigUnmarshalerType(typ reflect.Type) bool {
+	pointerType := reflect.PointerTo(typ)
+	return pointerType.Implements(yamlUnmarshalerType) || pointerType.Implements(textUnmarshalerType)
+}
+
+// getConfigFieldType returns the type of the field of the struct type with the YAML name.
+//
+// Fields with the inline flag are searched as if their fields were fields of the struct.
+func getConfigFieldType(structType reflect.Type, name string) (reflect.Type, bool) {
+	for i := range structType.NumField() {
+		field := structType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		tagName, tagFlags, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if tagName == "-" {
+			continue
+		}
+		if slices.Contains(strings.Split(tagFlags, ","), "inline") {
+			if fieldType, ok := getConfigChildType(field.Type, name); ok {
+				return fieldType, true
+			}
+			continue
+		}
+		if tagName == "" {
+			tagName = strings.ToLower(field.Name)
+		}
+		if tagName == name {
+			return field.Type, true
+		}
+	}
+	return nil, false
+}
+
+// getConfigElemType returns the type of the elements of the slice or array type, or nil
+// if the type is not a slice or array.
+func getConfigElemType(typ reflect.Type) reflect.Type {
+	typ = indirectConfigType(typ)
+	if typ == nil || (typ.Kind() != reflect.Slice && typ.Kind() != reflect.Array) {
+		return nil
+	}
+	return typ.Elem()
+}
+
+// indirectConfigType returns the type with all pointers removed.
+//
+// Returns nil if the type is nil.
+func indirectConfigType(typ reflect.Type) reflect.Type {
+	for typ != nil && typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	return typ
+}
+
+// isConfigStringType returns true if values of the type are decoded from strings only.
+func isConfigStringType(typ reflect.Type) bool {
+	typ = indirectConfigType(typ)
+	return typ != nil && typ.Kind() == reflect.String
+}
+
+// parseConfigString parses the string for a value of the type.
+//
+// The string is kept as is if the type is a string, so that for example "0755" is not
+// parsed as an octal number. If the type is a slice of strings, the string is parsed as
+// a YAML flow sequence with the elements kept as strings. Otherwise, including if the
+// type is nil, the string is parsed with parseConfigScalar.
+func parseConfigString(s string, typ reflect.Type) any {
+	if isConfigStringType(typ) {
+		return s
+	}
+	if isConfigStringType(getConfigElemType(typ)) {
+		var elements []string
+		if err := yaml.Unmarshal([]byte(s), &elements); err != nil {
+			return s
+		}
+		values := make([]any, len(elements))
+		for i, element := range elements {
+			values[i] = element
+		}
+		return values
+	}
+	return parseConfigScalar(s)
+}
@@ -0,0 +1,192 @@
+// Copyright 2025-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Matching the unix-like build tags in the Golang source i.e. https://github.com/golang/go/blob/912f0750472dd4f674b69ca1616bfaf377af1805/src/os/file_unix.go#L6
+
+//go:build aix || darwin || dragonfly || freebsd || (js && wasm) || linux || netbsd || openbsd || solaris
+
+package appext
+
+import (
+	"path/filepath"
+	"testing"
+
+	"buf.build/go/app"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadConfig(t *testing.T) {
+	t.Parallel()
+	container := testNewConfigContainer(
+		t,
+		map[string]string{
+			"FOO_BAR_CONFIG_DIR":  "/home/config",
+			"XDG_CONFIG_DIRS":     "/etc/xdg",
+			"FOO_BAR_SERVER_PORT": "9000",
+			"FOO_BAR_LOG_LEVEL":   "debug",
+		},
+		map[string]string{
+			"/etc/xdg/foo-bar/config.yaml": "name: system\nserver:\n  host: system\n  port: 1\n",
+			"/home/config/config.yaml":     "server:\n  host: user\n",
+			"/work/.foo-bar.yaml":          "tags: [project]\n",
+		},
+		"/work/sub",
+	)
+	flagSet := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flagSet.String("log-level", "", "")
+	flagSet.String("name", "", "")
+	require.NoError(t, flagSet.Parse([]string{"--log-level", "warn"}))
+	config := &testLayeredConfig{
+		Name:     "default",
+		LogLevel: "info",
+	}
+	sources, err := LoadConfig(container, config, ConfigWithFlagSet(flagSet))
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		&testLayeredConfig{
+			Name:     "system",
+			LogLevel: "warn",
+			Tags:     []string{"project"},
+			Server: testServerConfig{
+				Host: "user",
+				Port: 9000,
+			},
+		},
+		config,
+	)
+	assert.Equal(
+		t,
+		ConfigSources{
+			"name":        {Layer: ConfigLayerSystem, Path: filepath.Join("/etc/xdg/foo-bar", "config.yaml")},
+			"log_level":   {Layer: ConfigLayerFlag, Path: "log-level"},
+			"tags":        {Layer: ConfigLayerProject, Path: filepath.Join("/work", ".foo-bar.yaml")},
+			"server.host": {Layer: ConfigLayerUser, Path: filepath.Join("/home/config", "config.yaml")},
+			"server.port": {Layer: ConfigLayerEnv, Path: "FOO_BAR_SERVER_PORT"},
+		},
+		sources,
+	)
+}
+
+func TestLoadConfigStringOverrides(t *testing.T) {
+	t.Parallel()
+	container := testNewConfigContainer(
+		t,
+		map[string]string{
+			"FOO_BAR_NAME":        "0x1F",
+			"FOO_BAR_LOG_LEVEL":   "1e3",
+			"FOO_BAR_SERVER_PORT": "0x1F",
+		},
+		nil,
+		"/work",
+	)
+	flagSet := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flagSet.StringSlice("tags", nil, "")
+	flagSet.String("server-host", "", "")
+	require.NoError(t, flagSet.Parse([]string{"--tags", "0755,true", "--server-host", "0755"}))
+	config := &testLayeredConfig{}
+	_, err := LoadConfig(container, config, ConfigWithFlagSet(flagSet))
+	require.NoError(t, err)
+	// Values for string fields are kept as is, and only values for other fields are parsed.
+	assert.Equal(
+		t,
+		&testLayeredConfig{
+			Name:     "0x1F",
+			LogLevel: "1e3",
+			Tags:     []string{"0755", "true"},
+			Server: testServerConfig{
+				Host: "0755",
+				Port: 31,
+			},
+		},
+		config,
+	)
+}
+
+func TestLoadConfigTypeKeys(t *testing.T) {
+	t.Parallel()
+	container := testNewConfigContainer(
+		t,
+		map[string]string{
+			"FOO_BAR_SERVER_HOST": "0755",
+			"FOO_BAR_MODES":       "[a, b]",
+		},
+		nil,
+		"/work",
+	)
+	flagSet := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flagSet.Int("server-port", 0, "")
+	require.NoError(t, flagSet.Parse([]string{"--server-port", "8080"}))
+	// The default value has no keys, as all fields are nil and omitted.
+	config := &testPointerConfig{}
+	sources, err := LoadConfig(container, config, ConfigWithFlagSet(flagSet))
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		&testPointerConfig{
+			Server: &testServerConfig{
+				Host: "0755",
+				Port: 8080,
+			},
+			Modes: []string{"a", "b"},
+		},
+		config,
+	)
+	assert.Equal(
+		t,
+		ConfigSources{
+			"server.host": {Layer: ConfigLayerEnv, Path: "FOO_BAR_SERVER_HOST"},
+			"server.port": {Layer: ConfigLayerFlag, Path: "server-port"},
+			"modes":       {Layer: ConfigLayerEnv, Path: "FOO_BAR_MODES"},
+		},
+		sources,
+	)
+}
+
+func TestLoadConfigUnknownKey(t *testing.T) {
+	t.Parallel()
+	container := testNewConfigContainer(
+		t,
+		map[string]string{
+			"FOO_BAR_CONFIG_DIR": "/home/config",
+		},
+		map[string]string{
+			"/home/config/config.yaml": "unknown: true\n",
+		},
+		"/work",
+	)
+	_, err := LoadConfig(container, &testLayeredConfig{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown")
+}
+
+func testNewConfigContainer(
+	t *testing.T,
+	env map[string]string,
+	pathToData map[string]string,
+	workDirPath string,
+) NameContainer {
+	baseContainer := app.NewContainerForWorkDir(app.NewContainer(env, nil, nil, nil, "test"), workDirPath)
+	fileSystem := baseContainer.FileSystem()
+	require.NoError(t, fileSystem.MkdirAll(workDirPath, 0755))
+	for path, data := range pathToData {
+		require.NoError(t, fileSystem.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, fileSystem.WriteFile(path, []byte(data), 0644))
+	}
+	container, err := NewNameContainer(baseContainer, "foo-bar")
+	require.NoError(t, err)
+	return container
+}
@@ -0,0 +1,260 @@
+// Copyright 2025-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package appext
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"time"
+
+	"buf.build/go/app"
+)
+
+const defaultConfigWatcherInterval = 2 * time.Second
+
+type configWatcherOptions[T any] struct {
+	interval      time.Duration
+	validate      func(*T) error
+	callback      func(*T)
+	errorHandler  func(error)
+	configOptions []ConfigOption
+}
+
+func newConfigWatcherOptions[T any]() *configWatcherOptions[T] {
+	return &configWatcherOptions[T]{
+		interval: defaultConfigWatcherInterval,
+	}
+}
+
+type configWatcher[T any] struct {
+	container            NameContainer
+	configWatcherOptions *configWatcherOptions[T]
+	configOptions        *configOptions
+	updates              chan *T
+	cancel               context.CancelFunc
+	done                 chan struct{}
+
+	// reloadLock serializes reloads, and protects fingerprint and closed.
+	reloadLock  sync.Mutex
+	fingerprint []byte
+	closed      bool
+
+	lock   sync.RWMutex
+	config *T
+}
+
+func newConfigWatcher[T any](
+	ctx context.Context,
+	container NameContainer,
+	options ...ConfigWatcherOption[T],
+) (*configWatcher[T], error) {
+	configWatcherOptions := newConfigWatcherOptions[T]()
+	for _, option := range options {
+		option(configWatcherOptions)
+	}
+	if configWatcherOptions.interval <= 0 {
+		return nil, fmt.Errorf("invalid config watcher interval: %v", configWatcherOptions.interval)
+	}
+	configOptions := newConfigOptions(container.AppName())
+	for _, option := range configWatcherOptions.configOptions {
+		option(configOptions)
+	}
+	c := &configWatcher[T]{
+		container:            container,
+		configWatcherOptions: configWatcherOptions,
+		configOptions:        configOptions,
+		updates:              make(chan *T, 1),
+		done:                 make(chan struct{}),
+	}
+	fingerprint, err := c.getFingerprint()
+	if err != nil {
+		return nil, err
+	}
+	config, err := c.load()
+	if err != nil {
+		return nil, err
+	}
+	c.fingerprint = fingerprint
+	c.config = config
+	ctx, c.cancel = context.WithCancel(ctx)
+	// The timer is created here so that it is active when this function returns.
+	timer := container.Clock().NewTimer(configWatcherOptions.interval)
+	reloadSignalC, stopReloadSignal := notifyConfigReloadSignal()
+	go c.run(ctx, timer, reloadSignalC, stopReloadSignal)
+	return c, nil
+}
+
+func (c *configWatcher[T]) Config() *T {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.config
+}
+
+func (c *configWatcher[T]) Updates() <-chan *T {
+	return c.updates
+}
+
+func (c *configWatcher[T]) Reload() error {
+	return c.reload(true)
+}
+
+func (c *configWatcher[T]) Close() error {
+	c.cancel()
+	<-c.done
+	return nil
+}
+
+func (c *configWatcher[T]) run(
+	ctx context.Context,
+	timer app.Timer,
+	reloadSignalC <-chan os.Signal,
+	stopReloadSignal func(),
+) {
+	defer close(c.done)
+	defer c.closeUpdates()
+	defer stopReloadSignal()
+	defer timer.Stop()
+	for {
+		var err error
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C():
+			// Reset before reloading, so that the next poll is not delayed by the reload.
+			timer.Reset(c.configWatcherOptions.interval)
+			err = c.reload(false)
+		case <-reloadSignalC:
+			err = c.reload(true)
+		}
+		if err != nil && c.configWatcherOptions.errorHandler != nil {
+			c.configWatcherOptions.errorHandler(err)
+		}
+	}
+}
+
+// reload re-reads the configuration if any configuration file changed, or if force is true.
+//
+// The callback is called after reloadLock is released, so that it can call Reload.
+func (c *configWatcher[T]) reload(force bool) error {
+	config, err := c.reloadLocked(force)
+	if err != nil {
+		return err
+	}
+	if config != nil && c.configWatcherOptions.callback != nil {
+		c.configWatcherOptions.callback(config)
+	}
+	return nil
+}
+
+// reloadLocked re-reads the configuration while holding reloadLock.
+//
+// Returns the new configuration if it changed, or nil otherwise.
+func (c *configWatcher[T]) reloadLocked(force bool) (*T, error) {
+	c.reloadLock.Lock()
+	defer c.reloadLock.Unlock()
+	if c.closed {
+		return nil, errors.New("config watcher is closed")
+	}
+	fingerprint, err := c.getFingerprint()
+	if err != nil {
+		return nil, err
+	}
+	if !force && bytes.Equal(fingerprint, c.fingerprint) {
+		return nil, nil
+	}
+	// The fingerprint is updated even if the configuration is not valid, so that each
+	// change is only reported once.
+	c.fingerprint = fingerprint
+	config, err := c.load()
+	if err != nil {
+		return nil, err
+	}
+	c.lock.Lock()
+	changed := !reflect.DeepEqual(config, c.config)
+	if changed {
+		c.config = config
+	}
+	c.lock.Unlock()
+	if !changed {
+		return nil, nil
+	}
+	// Only the latest configuration is kept. As all sends happen while holding reloadLock,
+	// the send never blocks after the buffer is drained.
+	select {
+	case <-c.updates:
+	default:
+	}
+	c.updates <- config
+	return config, nil
+}
+
+// load reads and validates the configuration.
+func (c *configWatcher[T]) load() (*T, error) {
+	config := new(T)
+	if _, err := loadConfig(c.container, config, c.configOptions); err != nil {
+		return nil, err
+	}
+	if c.configWatcherOptions.validate != nil {
+		if err := c.configWatcherOptions.validate(config); err != nil {
+			return nil, fmt.Errorf("invalid %s configuration: %w", c.container.AppName(), err)
+		}
+	}
+	return config, nil
+}
+
+// getFingerprint returns a hash of the paths and contents of all configuration files
+// that LoadConfig may read.
+func (c *configWatcher[T]) getFingerprint() ([]byte, error) {
+	var filePaths []string
+	for _, configDirPath := range c.container.ConfigDirPaths() {
+		for _, codec := range c.configOptions.codecs {
+			filePaths = append(filePaths, filepath.Join(configDirPath, configFileBaseName+codec.FileExtension()))
+		}
+	}
+	projectFilePath, err := findProjectConfigFile(c.container, c.configOptions.projectFileName)
+	if err != nil {
+		return nil, err
+	}
+	if projectFilePath != "" {
+		filePaths = append(filePaths, projectFilePath)
+	}
+	hash := sha256.New()
+	for _, filePath := range filePaths {
+		data, err := c.container.FileSystem().ReadFile(filePath)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		_, _ = fmt.Fprintf(hash, "%s\x00%d\x00", filePath, len(data))
+		_, _ = hash.Write(data)
+	}
+	return hash.Sum(nil), nil
+}
+
+func (c *configWatcher[T]) closeUpdates() {
+	c.reloadLock.Lock()
+	defer c.reloadLock.Unlock()
+	c.closed = true
+	close(c.updates)
+}
@@ -0,0 +1,136 @@
+// Copyright 2025-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package appext
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"buf.build/go/app"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigWatcher(t *testing.T) {
+	t.Parallel()
+	clock := app.NewFakeClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	codecContainer := testNewCodecContainer(t, map[string]string{"config.yaml": "server:\n  port: 8080\n"})
+	container, err := NewNameContainer(app.NewContainerForClock(codecContainer, clock), "foo-bar")
+	require.NoError(t, err)
+	configFilePath := filepath.Join("config", "config.yaml")
+	errC := make(chan error, 1)
+	callbackC := make(chan *testLayeredConfig, 1)
+	configWatcher, err := NewConfigWatcher(
+		context.Background(),
+		container,
+		ConfigWatcherWithInterval[testLayeredConfig](time.Second),
+		ConfigWatcherWithValidate(
+			func(config *testLayeredConfig) error {
+				if config.Server.Port == 0 {
+					return errors.New("port is required")
+				}
+				return nil
+			},
+		),
+		ConfigWatcherWithCallback(
+			func(config *testLayeredConfig) {
+				callbackC <- config
+			},
+		),
+		ConfigWatcherWithErrorHandler[testLayeredConfig](
+			func(err error) {
+				errC <- err
+			},
+		),
+	)
+	require.NoError(t, err)
+	require.Equal(t, 8080, configWatcher.Config().Server.Port)
+
+	require.NoError(t, container.FileSystem().WriteFile(configFilePath, []byte("server:\n  port: 9090\n"), 0644))
+	clock.Advance(time.Second)
+	config := testReceive(t, configWatcher.Updates())
+	require.Equal(t, 9090, config.Server.Port)
+	require.Equal(t, config, testReceive(t, callbackC))
+	require.Equal(t, config, configWatcher.Config())
+
+	// Invalid configurations are reported, and the last good configuration is kept.
+	require.NoError(t, container.FileSystem().WriteFile(configFilePath, []byte("server:\n  port: 0\n"), 0644))
+	clock.Advance(time.Second)
+	require.ErrorContains(t, testReceive(t, errC), "port is required")
+	require.Equal(t, config, configWatcher.Config())
+	require.ErrorContains(t, configWatcher.Reload(), "port is required")
+	require.NoError(t, container.FileSystem().WriteFile(configFilePath, []byte("server:\n  port: 7070\n  unknown: true\n"), 0644))
+	require.Error(t, configWatcher.Reload())
+	require.Equal(t, config, configWatcher.Config())
+
+	require.NoError(t, container.FileSystem().WriteFile(configFilePath, []byte("server:\n  port: 7070\n"), 0644))
+	require.NoError(t, configWatcher.Reload())
+	config = testReceive(t, configWatcher.Updates())
+	require.Equal(t, 7070, config.Server.Port)
+	require.Equal(t, config, testReceive(t, callbackC))
+
+	require.NoError(t, configWatcher.Close())
+	_, ok := <-configWatcher.Updates()
+	require.False(t, ok)
+	require.Error(t, configWatcher.Reload())
+}
+
+func TestConfigWatcherCallbackReload(t *testing.T) {
+	t.Parallel()
+	clock := app.NewFakeClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	codecContainer := testNewCodecContainer(t, map[string]string{"config.yaml": "server:\n  port: 8080\n"})
+	container, err := NewNameContainer(app.NewContainerForClock(codecContainer, clock), "foo-bar")
+	require.NoError(t, err)
+	var configWatcher ConfigWatcher[testLayeredConfig]
+	reloadErrC := make(chan error, 1)
+	configWatcher, err = NewConfigWatcher(
+		context.Background(),
+		container,
+		ConfigWatcherWithInterval[testLayeredConfig](time.Second),
+		// The callback can call Reload, which does not deadlock.
+		ConfigWatcherWithCallback(
+			func(*testLayeredConfig) {
+				reloadErrC <- configWatcher.Reload()
+			},
+		),
+	)
+	require.NoError(t, err)
+	require.NoError(t, container.FileSystem().WriteFile(filepath.Join("config", "config.yaml"), []byte("server:\n  port: 9090\n"), 0644))
+	clock.Advance(time.Second)
+	require.NoError(t, testReceive(t, reloadErrC))
+	require.Equal(t, 9090, testReceive(t, configWatcher.Updates()).Server.Port)
+	require.NoError(t, configWatcher.Close())
+}
+
+func TestConfigWatcherInvalidInitial(t *testing.T) {
+	t.Parallel()
+	container := testNewCodecContainer(t, map[string]string{"config.yaml": "unknown: true\n"})
+	_, err := NewConfigWatcher[testLayeredConfig](context.Background(), container)
+	require.Error(t, err)
+}
+
+func testReceive[T any](t *testing.T, c <-chan T) T {
+	t.Helper()
+	select {
+	case value := <-c:
+		return value
+	case <-time.After(10 * time.Second):
+		require.FailNow(t, "timed out waiting to receive")
+		var zero T
+		return zero
+	}
+}
@@ -0,0 +1,33 @@
+// Copyright 2025-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Excluding js,wasm from the unix-like build tags, as there are no signals to receive there.
+
+//go:build aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+
+package appext
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// notifyConfigReloadSignal returns a channel that receives SIGHUP, and a function to stop
+// receiving it.
+func notifyConfigReloadSignal() (<-chan os.Signal, func()) {
+	signalC := make(chan os.Signal, 1)
+	signal.Notify(signalC, syscall.SIGHUP)
+	return signalC, func() { signal.Stop(signalC) }
+}
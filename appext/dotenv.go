@@ -0,0 +1,55 @@
+// Copyright 2025-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package appext
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"buf.build/go/app"
+)
+
+// loadDotenv returns a new app.Container with the environment variables from the
+// .env files in the working directory and the configuration directory layered under
+// the environment of the container.
+func loadDotenv(container app.Container, appName string) (app.Container, error) {
+	nameContainer, err := newNameContainer(container, appName)
+	if err != nil {
+		return nil, err
+	}
+	dotenvFilePaths := []string{
+		app.ResolvePath(container, dotenvFileName),
+	}
+	if configDirPath := nameContainer.ConfigDirPath(); configDirPath != "" {
+		dotenvFilePaths = append(dotenvFilePaths, filepath.Join(configDirPath, dotenvFileName))
+	}
+	var envContainer app.EnvContainer = container
+	for _, dotenvFilePath := range dotenvFilePaths {
+		data, err := container.FileSystem().ReadFile(dotenvFilePath)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		envContainer, err = app.NewEnvContainerForDotenv(envContainer, data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", dotenvFilePath, err)
+		}
+	}
+	return app.NewContainerForEnv(container, envContainer), nil
+}
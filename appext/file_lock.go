@@ -0,0 +1,252 @@
+// Copyright 2025-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package appext
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"buf.build/go/app"
+)
+
+const fileLockPollInterval = 50 * time.Millisecond
+
+type lockOptions struct {
+	shared bool
+	// timeout is how long to wait for the lock.
+	//
+	// A negative timeout means to wait until the context is done.
+	timeout time.Duration
+	// waitWriter is where to print the holder of the lock when waiting, if not nil.
+	waitWriter io.Writer
+}
+
+func newLockOptions() *lockOptions {
+	return &lockOptions{
+		timeout: -1,
+	}
+}
+
+// fileLockHolder is the holder of a lock, as stored in lock files.
+type fileLockHolder struct {
+	PID      int       `json:"pid"`
+	Hostname string    `json:"hostname,omitempty"`
+	Time     time.Time `json:"time,omitzero"`
+}
+
+func (h *fileLockHolder) String() string {
+	var builder strings.Builder
+	_, _ = fmt.Fprintf(&builder, "process %d", h.PID)
+	if h.Hostname != "" {
+		_, _ = fmt.Fprintf(&builder, " on %s", h.Hostname)
+	}
+	if !h.Time.IsZero() {
+		_, _ = fmt.Fprintf(&builder, " since %s", h.Time.Format(time.RFC3339))
+	}
+	return builder.String()
+}
+
+type fileUnlocker struct {
+	unlock func() error
+	once   sync.Once
+	err    error
+}
+
+func newFileUnlocker(unlock func() error) *fileUnlocker {
+	return &fileUnlocker{
+		unlock: unlock,
+	}
+}
+
+func (f *fileUnlocker) Unlock() error {
+	f.once.Do(func() { f.err = f.unlock() })
+	return f.err
+}
+
+// lockDir acquires the lock with the name in the directory.
+func lockDir(ctx context.Context, container NameContainer, dirPath string, name string, options ...LockOption) (Unlocker, error) {
+	if err := validateLockName(name); err != nil {
+		return nil, err
+	}
+	if dirPath == "" {
+		return nil, fmt.Errorf("no directory for %s lock %s", container.AppName(), name)
+	}
+	lockOptions := newLockOptions()
+	lockOptions.waitWriter = container.Stderr()
+	for _, option := range options {
+		option(lockOptions)
+	}
+	if err := container.FileSystem().MkdirAll(dirPath, 0755); err != nil {
+		return nil, err
+	}
+	unlock, err := acquireFileLock(ctx, container, filepath.Join(dirPath, name+".lock"), lockOptions)
+	if err != nil {
+		return nil, err
+	}
+	return newFileUnlocker(unlock), nil
+}
+
+// acquireFileLock acquires the advisory lock of the FileSystem on the lock file.
+//
+// Exclusive holders write the holder as JSON to the lock file, so that waiters can print
+// who holds the lock, and clear it when releasing the lock. The lock file is not removed.
+// The lock is released by the operating system if the holder exits without releasing it.
+// Waiting is measured with the Clock of the container, and fails after the timeout.
+//
+// Returns a function that releases the lock.
+func acquireFileLock(
+	ctx context.Context,
+	container app.Container,
+	lockFilePath string,
+	lockOptions *lockOptions,
+) (func() error, error) {
+	fileLocker := &fileLocker{
+		container:    container,
+		lockFilePath: lockFilePath,
+		lockOptions:  lockOptions,
+	}
+	if lockOptions.timeout >= 0 {
+		fileLocker.deadline = container.Clock().Now().Add(lockOptions.timeout)
+	}
+	return fileLocker.acquire(ctx)
+}
+
+type fileLocker struct {
+	container    app.Container
+	lockFilePath string
+	lockOptions  *lockOptions
+	// deadline is zero if there is no timeout.
+	deadline time.Time
+	// printed is true if the wait message was printed.
+	printed bool
+}
+
+func (f *fileLocker) acquire(ctx context.Context) (func() error, error) {
+	fileSystem := f.container.FileSystem()
+	for {
+		unlock, err := fileSystem.TryLock(f.lockFilePath, f.lockOptions.shared)
+		if err == nil {
+			if f.lockOptions.shared {
+				return unlock, nil
+			}
+			if err := f.writeHolder(); err != nil {
+				return nil, errors.Join(err, unlock())
+			}
+			return func() error {
+				return errors.Join(fileSystem.WriteFile(f.lockFilePath, nil, 0644), unlock())
+			}, nil
+		}
+		if !errors.Is(err, app.ErrLocked) {
+			return nil, err
+		}
+		// Shared holders do not write the holder, and the holder may not have written it yet.
+		holder, _ := readFileLockHolder(fileSystem, f.lockFilePath)
+		if err := f.wait(ctx, holder); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// wait waits for the poll interval, printing the wait message the first time.
+//
+// The holder is nil if it is not known.
+func (f *fileLocker) wait(ctx context.Context, holder *fileLockHolder) error {
+	clock := f.container.Clock()
+	if !f.deadline.IsZero() && !clock.Now().Before(f.deadline) {
+		if holder != nil {
+			return fmt.Errorf("timed out waiting for lock %s held by %s: %w", f.lockFilePath, holder, app.ErrLocked)
+		}
+		return fmt.Errorf("timed out waiting for lock %s: %w", f.lockFilePath, app.ErrLocked)
+	}
+	if !f.printed && f.lockOptions.waitWriter != nil {
+		f.printed = true
+		if holder != nil {
+			_, _ = fmt.Fprintf(f.lockOptions.waitWriter, "Waiting for lock %s held by %s...\n", f.lockFilePath, holder)
+		} else {
+			_, _ = fmt.Fprintf(f.lockOptions.waitWriter, "Waiting for lock %s...\n", f.lockFilePath)
+		}
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-clock.After(fileLockPollInterval):
+		return nil
+	}
+}
+
+// writeHolder writes the holder for this process to the lock file.
+func (f *fileLocker) writeHolder() error {
+	data, err := json.Marshal(
+		&fileLockHolder{
+			PID:      os.Getpid(),
+			Hostname: getHostname(),
+			Time:     f.container.Clock().Now().UTC(),
+		},
+	)
+	if err != nil {
+		return err
+	}
+	return f.container.FileSystem().WriteFile(f.lockFilePath, data, 0644)
+}
+
+// readFileLockHolder reads the holder from the lock file.
+//
+// Lock files that only contain the PID are also accepted.
+// Returns false if the holder cannot be read, for example if the holder has not finished writing it.
+func readFileLockHolder(fileSystem app.FileSystem, lockFilePath string) (*fileLockHolder, bool) {
+	data, err := fileSystem.ReadFile(lockFilePath)
+	if err != nil {
+		return nil, false
+	}
+	holder := &fileLockHolder{}
+	if err := json.Unmarshal(data, holder); err != nil {
+		pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+		if err != nil {
+			return nil, false
+		}
+		holder.PID = pid
+	}
+	if holder.PID <= 0 {
+		return nil, false
+	}
+	return holder, true
+}
+
+// getHostname returns the hostname, or empty if it cannot be determined.
+func getHostname() string {
+	hostname, _ := os.Hostname()
+	return hostname
+}
+
+func validateLockName(name string) error {
+	if name == "" {
+		return errors.New("empty lock name")
+	}
+	for _, c := range name {
+		if !((c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') || c == '-' || c == '_') {
+			return fmt.Errorf("invalid lock name: %s", name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+// Copyright 2025-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package appext
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"buf.build/go/app"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateConfigConcurrent(t *testing.T) {
+	t.Parallel()
+	container := testNewCodecContainer(t, nil)
+	var waitGroup sync.WaitGroup
+	for range 10 {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			assert.NoError(
+				t,
+				UpdateConfig(
+					container,
+					func(config *testServerConfig) error {
+						config.Port++
+						return nil
+					},
+				),
+			)
+		}()
+	}
+	waitGroup.Wait()
+	config := &testServerConfig{}
+	require.NoError(t, ReadConfig(container, config))
+	assert.Equal(t, 10, config.Port)
+	entries, err := container.FileSystem().ReadDir("config")
+	require.NoError(t, err)
+	// The lock file is kept, and no temporary files are left.
+	require.Len(t, entries, 2)
+	assert.Equal(t, configLockFileName, entries[0].Name())
+	assert.Equal(t, "config.yaml", entries[1].Name())
+}
+
+func TestUpdateConfigError(t *testing.T) {
+	t.Parallel()
+	container := testNewCodecContainer(t, map[string]string{"config.yaml": "port: 1\n"})
+	err := UpdateConfig(
+		container,
+		func(config *testServerConfig) error {
+			config.Port = 2
+			return os.ErrInvalid
+		},
+	)
+	require.ErrorIs(t, err, os.ErrInvalid)
+	config := &testServerConfig{}
+	require.NoError(t, ReadConfig(container, config))
+	assert.Equal(t, 1, config.Port)
+}
+
+func TestWriteConfigLockTimeout(t *testing.T) {
+	t.Parallel()
+	container := testNewCodecContainer(t, nil)
+	unlock, err := acquireFileLock(t.Context(), container, filepath.Join("config", configLockFileName), &lockOptions{})
+	require.NoError(t, err)
+	err = WriteConfig(container, &testServerConfig{}, ConfigWithLockTimeout(0))
+	require.ErrorIs(t, err, app.ErrLocked)
+	assert.Contains(t, err.Error(), "held by process "+strconv.Itoa(os.Getpid()))
+	_, err = container.FileSystem().Stat(filepath.Join("config", "config.yaml"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+	// Waiting ends when the context is done, even if the Clock does not advance.
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	clockContainer, err := NewNameContainer(
+		app.NewContainerForClock(container, app.NewFakeClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))),
+		"foo-bar",
+	)
+	require.NoError(t, err)
+	err = WriteConfigContext(ctx, clockContainer, &testServerConfig{})
+	require.ErrorIs(t, err, context.Canceled)
+	require.NoError(t, unlock())
+	require.NoError(t, WriteConfig(container, &testServerConfig{}, ConfigWithLockTimeout(0)))
+}
+
+func TestAcquireFileLockWaits(t *testing.T) {
+	t.Parallel()
+	clock := app.NewFakeClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	container := app.NewContainerForClock(app.NewContainer(nil, nil, nil, nil), clock)
+	unlock, err := acquireFileLock(t.Context(), container, "lock", &lockOptions{timeout: time.Minute})
+	require.NoError(t, err)
+	acquired := make(chan error, 1)
+	go func() {
+		unlock, err := acquireFileLock(t.Context(), container, "lock", &lockOptions{timeout: time.Minute})
+		if err == nil {
+			err = unlock()
+		}
+		acquired <- err
+	}()
+	require.NoError(t, unlock())
+	// Advance until the waiting goroutine polls again and acquires the lock.
+	for {
+		select {
+		case err := <-acquired:
+			require.NoError(t, err)
+			return
+		default:
+			clock.Advance(fileLockPollInterval)
+			time.Sleep(time.Millisecond)
+		}
+	}
+}
+
+func TestLockCacheDir(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	stderr := bytes.NewBuffer(nil)
+	container, err := NewNameContainer(
+		app.NewContainer(map[string]string{"FOO_BAR_CACHE_DIR": "cache", "FOO_BAR_DATA_DIR": "data"}, nil, nil, stderr),
+		"foo-bar",
+	)
+	require.NoError(t, err)
+	sharedUnlocker1, err := LockCacheDir(ctx, container, "modules", LockWithShared())
+	require.NoError(t, err)
+	sharedUnlocker2, err := LockCacheDir(ctx, container, "modules", LockWithShared(), LockWithTimeout(0))
+	require.NoError(t, err)
+	_, err = LockCacheDir(ctx, container, "modules", LockWithTimeout(2*fileLockPollInterval))
+	require.ErrorIs(t, err, app.ErrLocked)
+	assert.Contains(t, stderr.String(), "Waiting for lock "+filepath.Join("cache", "modules.lock")+"...")
+	// The failed exclusive lock does not block shared locks.
+	sharedUnlocker3, err := LockCacheDir(ctx, container, "modules", LockWithShared(), LockWithTimeout(0))
+	require.NoError(t, err)
+	require.NoError(t, sharedUnlocker1.Unlock())
+	require.NoError(t, sharedUnlocker2.Unlock())
+	require.NoError(t, sharedUnlocker3.Unlock())
+	require.NoError(t, sharedUnlocker3.Unlock())
+
+	unlocker, err := LockCacheDir(ctx, container, "modules", LockWithTimeout(0))
+	require.NoError(t, err)
+	_, err = LockCacheDir(ctx, container, "modules", LockWithShared(), LockWithTimeout(0))
+	require.ErrorIs(t, err, app.ErrLocked)
+	assert.Contains(t, err.Error(), "held by process "+strconv.Itoa(os.Getpid()))
+	// Locks with other names are independent.
+	otherUnlocker, err := LockCacheDir(ctx, container, "blobs", LockWithTimeout(0))
+	require.NoError(t, err)
+	require.NoError(t, otherUnlocker.Unlock())
+	require.NoError(t, unlocker.Unlock())
+	// The lock files are kept, and the holders are cleared.
+	data, err := container.FileSystem().ReadFile(filepath.Join("cache", "modules.lock"))
+	require.NoError(t, err)
+	assert.Empty(t, data)
+
+	canceledCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	unlocker, err = LockDataDir(ctx, container, "modules")
+	require.NoError(t, err)
+	_, err = LockDataDir(canceledCtx, container, "modules")
+	require.ErrorIs(t, err, context.Canceled)
+	require.NoError(t, unlocker.Unlock())
+	_, err = LockCacheDir(ctx, container, "../modules")
+	require.Error(t, err)
+}
@@ -0,0 +1,148 @@
+// Copyright 2025-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Excluding js,wasm from the unix-like build tags, as programs cannot be run there.
+
+//go:build aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+
+package appext
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"buf.build/go/app"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAcquireFileLockAbandoned(t *testing.T) {
+	t.Parallel()
+	// The PID of an exited process, whose lock was released by the operating system.
+	cmd := exec.Command("/bin/sh", "-c", "exit 0")
+	require.NoError(t, cmd.Run())
+	container := app.NewContainerForFileSystem(app.NewContainer(nil, nil, nil, nil), app.NewFileSystemForOS())
+	lockFilePath := filepath.Join(t.TempDir(), "lock")
+	data, err := json.Marshal(&fileLockHolder{PID: cmd.Process.Pid})
+	require.NoError(t, err)
+	require.NoError(t, container.FileSystem().WriteFile(lockFilePath, data, 0644))
+	unlock, err := acquireFileLock(t.Context(), container, lockFilePath, &lockOptions{})
+	require.NoError(t, err)
+	holder, ok := readFileLockHolder(container.FileSystem(), lockFilePath)
+	require.True(t, ok)
+	assert.Equal(t, os.Getpid(), holder.PID)
+	_, err = acquireFileLock(t.Context(), container, lockFilePath, &lockOptions{})
+	require.ErrorIs(t, err, app.ErrLocked)
+	assert.Contains(t, err.Error(), "held by process "+strconv.Itoa(os.Getpid()))
+	require.NoError(t, unlock())
+	_, ok = readFileLockHolder(container.FileSystem(), lockFilePath)
+	assert.False(t, ok)
+}
+
+func TestLockDataDirOS(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	container := testNewLockDataDirContainer(t, t.TempDir())
+	sharedUnlocker1, err := LockDataDir(ctx, container, "modules", LockWithShared(), LockWithTimeout(0))
+	require.NoError(t, err)
+	sharedUnlocker2, err := LockDataDir(ctx, container, "modules", LockWithShared(), LockWithTimeout(0))
+	require.NoError(t, err)
+	_, err = LockDataDir(ctx, container, "modules", LockWithTimeout(0))
+	require.ErrorIs(t, err, app.ErrLocked)
+	require.NoError(t, sharedUnlocker1.Unlock())
+	require.NoError(t, sharedUnlocker2.Unlock())
+	unlocker, err := LockDataDir(ctx, container, "modules", LockWithTimeout(0))
+	require.NoError(t, err)
+	_, err = LockDataDir(ctx, container, "modules", LockWithShared(), LockWithTimeout(0))
+	require.ErrorIs(t, err, app.ErrLocked)
+	assert.Contains(t, err.Error(), "held by process "+strconv.Itoa(os.Getpid()))
+	require.NoError(t, unlocker.Unlock())
+}
+
+func TestLockDataDirProcess(t *testing.T) {
+	// The child process holds the lock until it is killed.
+	if dataDirPath := os.Getenv("TEST_LOCK_DATA_DIR_PATH"); dataDirPath != "" {
+		container := testNewLockDataDirContainer(t, dataDirPath)
+		_, err := LockDataDir(t.Context(), container, "modules", LockWithTimeout(0))
+		require.NoError(t, err)
+		_, err = os.Stdout.WriteString("locked\n")
+		require.NoError(t, err)
+		_, _ = io.Copy(io.Discard, os.Stdin)
+		return
+	}
+	t.Parallel()
+	dataDirPath := t.TempDir()
+	cmd := exec.Command(os.Args[0], "-test.run=^TestLockDataDirProcess$")
+	cmd.Env = append(os.Environ(), "TEST_LOCK_DATA_DIR_PATH="+dataDirPath)
+	stdin, err := cmd.StdinPipe()
+	require.NoError(t, err)
+	defer func() { _ = stdin.Close() }()
+	stdout, err := cmd.StdoutPipe()
+	require.NoError(t, err)
+	require.NoError(t, cmd.Start())
+	line, err := bufio.NewReader(stdout).ReadString('\n')
+	require.NoError(t, err)
+	require.Equal(t, "locked\n", line)
+	container := testNewLockDataDirContainer(t, dataDirPath)
+	_, err = LockDataDir(t.Context(), container, "modules", LockWithShared(), LockWithTimeout(0))
+	require.ErrorIs(t, err, app.ErrLocked)
+	assert.Contains(t, err.Error(), "held by process "+strconv.Itoa(cmd.Process.Pid))
+	// The lock is released by the operating system when the child process exits without
+	// releasing it.
+	require.NoError(t, cmd.Process.Kill())
+	require.Error(t, cmd.Wait())
+	unlocker, err := LockDataDir(t.Context(), container, "modules", LockWithTimeout(0))
+	require.NoError(t, err)
+	require.NoError(t, unlocker.Unlock())
+}
+
+func TestWriteFileAtomicSymlink(t *testing.T) {
+	t.Parallel()
+	fileSystem := app.NewFileSystemForOS()
+	dirPath := t.TempDir()
+	targetFilePath := filepath.Join(dirPath, "target.yaml")
+	linkFilePath := filepath.Join(dirPath, "config.yaml")
+	require.NoError(t, fileSystem.WriteFile(targetFilePath, []byte("foo: 1\n"), 0600))
+	require.NoError(t, os.Symlink("target.yaml", linkFilePath))
+	require.NoError(t, writeFileAtomic(fileSystem, linkFilePath, []byte("foo: 2\n"), 0600))
+	fileInfo, err := os.Lstat(linkFilePath)
+	require.NoError(t, err)
+	assert.Equal(t, os.ModeSymlink, fileInfo.Mode().Type())
+	data, err := fileSystem.ReadFile(targetFilePath)
+	require.NoError(t, err)
+	assert.Equal(t, "foo: 2\n", string(data))
+	// Files that do not exist are created.
+	require.NoError(t, writeFileAtomic(fileSystem, filepath.Join(dirPath, "new.yaml"), []byte("foo: 3\n"), 0600))
+	data, err = fileSystem.ReadFile(filepath.Join(dirPath, "new.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "foo: 3\n", string(data))
+}
+
+func testNewLockDataDirContainer(t *testing.T, dataDirPath string) NameContainer {
+	container, err := NewNameContainer(
+		app.NewContainerForFileSystem(
+			app.NewContainer(map[string]string{"FOO_BAR_DATA_DIR": dataDirPath}, nil, nil, nil),
+			app.NewFileSystemForOS(),
+		),
+		"foo-bar",
+	)
+	require.NoError(t, err)
+	return container
+}
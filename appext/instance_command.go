@@ -0,0 +1,37 @@
+// Copyright 2025-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package appext
+
+import (
+	"context"
+	"fmt"
+)
+
+func runInstanceStatus(_ context.Context, container Container) error {
+	instanceStatus, err := getInstanceStatus(container)
+	if err != nil {
+		return err
+	}
+	if !instanceStatus.Running {
+		_, err := fmt.Fprintf(container.Stdout(), "%s is not running\n", container.AppName())
+		return err
+	}
+	_, err = fmt.Fprintf(container.Stdout(), "%s is running as process %d\n", container.AppName(), instanceStatus.PID)
+	return err
+}
+
+func runInstanceStop(ctx context.Context, container Container) error {
+	return stopInstance(ctx, container)
+}
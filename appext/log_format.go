@@ -23,10 +23,10 @@ import (
 const (
 	// LogFormatText is the text log format.
 	LogFormatText LogFormat = iota + 1
-	// LogFormatColor is the colored text log format.
+	// LogFormatColor is the colored, human-oriented text log format.
 	//
-	// This is the default value when parsing LogFormats. However, unless BuilderWithLoggerProvider
-	// is used, there is no difference between LogFormatText and LogFormatColor.
+	// This is the default value when parsing LogFormats. Unless BuilderWithLoggerProvider
+	// is used, colors are only printed if stderr is a terminal and $NO_COLOR is not set.
 	LogFormatColor
 	// LogFormatJSON is the JSON log format.
 	LogFormatJSON
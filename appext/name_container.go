@@ -30,15 +30,19 @@ type nameContainer struct {
 
 	appName string
 
-	configDirPath     string
-	configDirPathOnce sync.Once
-	cacheDirPath      string
-	cacheDirPathOnce  sync.Once
-	dataDirPath       string
-	dataDirPathOnce   sync.Once
-	port              uint16
-	portErr           error
-	portOnce          sync.Once
+	configDirPath      string
+	configDirPathOnce  sync.Once
+	cacheDirPath       string
+	cacheDirPathOnce   sync.Once
+	dataDirPath        string
+	dataDirPathOnce    sync.Once
+	stateDirPath       string
+	stateDirPathOnce   sync.Once
+	runtimeDirPath     string
+	runtimeDirPathOnce sync.Once
+	port               uint16
+	portErr            error
+	portOnce           sync.Once
 }
 
 func newNameContainer(baseContainer app.Container, appName string) (*nameContainer, error) {
@@ -70,6 +74,28 @@ func (c *nameContainer) DataDirPath() string {
 	return c.dataDirPath
 }
 
+func (c *nameContainer) StateDirPath() string {
+	c.stateDirPathOnce.Do(c.setStateDirPath)
+	return c.stateDirPath
+}
+
+func (c *nameContainer) RuntimeDirPath() string {
+	c.runtimeDirPathOnce.Do(c.setRuntimeDirPath)
+	return c.runtimeDirPath
+}
+
+func (c *nameContainer) ConfigDirPaths() []string {
+	return c.getDirPaths(c.ConfigDirPath(), app.SystemConfigDirPaths)
+}
+
+func (c *nameContainer) DataDirPaths() []string {
+	return c.getDirPaths(c.DataDirPath(), app.SystemDataDirPaths)
+}
+
+func (c *nameContainer) Profile() string {
+	return c.Container.Env(getAppNameEnvPrefix(c.appName) + profileEnvSuffix)
+}
+
 func (c *nameContainer) Port() (uint16, error) {
 	c.portOnce.Do(c.setPort)
 	return c.port, c.portErr
@@ -87,6 +113,14 @@ func (c *nameContainer) setDataDirPath() {
 	c.dataDirPath = c.getDirPath("DATA_DIR", app.DataDirPath)
 }
 
+func (c *nameContainer) setStateDirPath() {
+	c.stateDirPath = c.getDirPath("STATE_DIR", app.StateDirPath)
+}
+
+func (c *nameContainer) setRuntimeDirPath() {
+	c.runtimeDirPath = c.getDirPath("RUNTIME_DIR", app.RuntimeDirPath)
+}
+
 func (c *nameContainer) setPort() {
 	c.port, c.portErr = c.getPort()
 }
@@ -102,6 +136,17 @@ func (c *nameContainer) getDirPath(envSuffix string, getBaseDirPath func(app.Env
 	return dirPath
 }
 
+func (c *nameContainer) getDirPaths(dirPath string, getSystemDirPaths func(app.EnvContainer) []string) []string {
+	var dirPaths []string
+	if dirPath != "" {
+		dirPaths = append(dirPaths, dirPath)
+	}
+	for _, systemDirPath := range getSystemDirPaths(c.Container) {
+		dirPaths = append(dirPaths, filepath.Join(systemDirPath, c.appName))
+	}
+	return dirPaths
+}
+
 func (c *nameContainer) getPort() (uint16, error) {
 	portString := c.Container.Env(getAppNameEnvPrefix(c.appName) + "PORT")
 	if portString == "" {
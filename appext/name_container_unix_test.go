@@ -113,6 +113,90 @@ func TestPort4(t *testing.T) {
 	)
 }
 
+func TestStateAndRuntimeDirPath(t *testing.T) {
+	t.Parallel()
+	container, err := NewNameContainer(
+		testNewContainer(
+			map[string]string{
+				"HOME":                "/home/foo",
+				"FOO_BAR_RUNTIME_DIR": "/run/foo-bar",
+			},
+		),
+		"foo-bar",
+	)
+	require.NoError(t, err)
+	require.Equal(t, "/home/foo/.local/state/foo-bar", container.StateDirPath())
+	require.Equal(t, "/run/foo-bar", container.RuntimeDirPath())
+	container, err = NewNameContainer(
+		testNewContainer(
+			map[string]string{
+				"XDG_STATE_HOME": "/state",
+			},
+		),
+		"foo-bar",
+	)
+	require.NoError(t, err)
+	require.Equal(t, "/state/foo-bar", container.StateDirPath())
+	require.Equal(t, "", container.RuntimeDirPath())
+	container, err = NewNameContainer(
+		testNewContainer(
+			map[string]string{
+				"XDG_RUNTIME_DIR": "/run/user/1000",
+			},
+		),
+		"foo-bar",
+	)
+	require.NoError(t, err)
+	require.Equal(t, "/run/user/1000/foo-bar", container.RuntimeDirPath())
+}
+
+func TestConfigDirPaths(t *testing.T) {
+	t.Parallel()
+	container, err := NewNameContainer(
+		testNewContainer(
+			map[string]string{
+				"HOME":            "/home/foo",
+				"XDG_CONFIG_DIRS": "/etc/one:relative:/etc/two",
+			},
+		),
+		"foo-bar",
+	)
+	require.NoError(t, err)
+	require.Equal(
+		t,
+		[]string{"/home/foo/.config/foo-bar", "/etc/one/foo-bar", "/etc/two/foo-bar"},
+		container.ConfigDirPaths(),
+	)
+	container, err = NewNameContainer(testNewContainer(nil), "foo-bar")
+	require.NoError(t, err)
+	require.Equal(t, []string{"/etc/xdg/foo-bar"}, container.ConfigDirPaths())
+	require.Equal(t, []string{"/usr/local/share/foo-bar", "/usr/share/foo-bar"}, container.DataDirPaths())
+}
+
+func TestReadConfigSystemFallback(t *testing.T) {
+	t.Parallel()
+	container, err := NewNameContainer(
+		testNewContainer(
+			map[string]string{
+				"HOME":            "/home/foo",
+				"XDG_CONFIG_DIRS": "/etc/one:/etc/two",
+			},
+		),
+		"foo-bar",
+	)
+	require.NoError(t, err)
+	fileSystem := container.FileSystem()
+	require.NoError(t, fileSystem.MkdirAll("/etc/two/foo-bar", 0755))
+	require.NoError(t, fileSystem.WriteFile("/etc/two/foo-bar/config.yaml", []byte("bar: system\n"), 0644))
+	config := &testConfig{}
+	require.NoError(t, ReadConfig(container, config))
+	require.Equal(t, &testConfig{Bar: "system"}, config)
+	require.NoError(t, WriteConfig(container, &testConfig{Bar: "user"}))
+	config = &testConfig{}
+	require.NoError(t, ReadConfig(container, config))
+	require.Equal(t, &testConfig{Bar: "user"}, config)
+}
+
 func testPort(t *testing.T, appName string, env map[string]string, expected uint16) {
 	container, err := NewNameContainer(testNewContainer(env), appName)
 	require.NoError(t, err)
@@ -122,15 +206,18 @@ func testPort(t *testing.T, appName string, env map[string]string, expected uint
 }
 
 func testRoundTrip(t *testing.T, appName string, env map[string]string, dirPath string) {
-	_, err := os.Lstat(filepath.Join(dirPath, configFileName))
-	require.Error(t, err)
 	container, err := NewNameContainer(testNewContainer(env), appName)
 	require.NoError(t, err)
+	_, err = container.FileSystem().Stat(filepath.Join(dirPath, configFileBaseName+".yaml"))
+	require.Error(t, err)
 	inputTestConfig := &testConfig{Bar: "one", Baz: "two"}
 	err = WriteConfig(container, inputTestConfig)
 	require.NoError(t, err)
-	_, err = os.Lstat(filepath.Join(dirPath, configFileName))
+	_, err = container.FileSystem().Stat(filepath.Join(dirPath, configFileBaseName+".yaml"))
 	require.NoError(t, err)
+	// The container uses an in-memory file system, so nothing should be written to disk.
+	_, err = os.Lstat(filepath.Join(dirPath, configFileBaseName+".yaml"))
+	require.ErrorIs(t, err, os.ErrNotExist)
 	outputTestConfig := &testConfig{}
 	err = ReadConfig(container, outputTestConfig)
 	require.NoError(t, err)
@@ -0,0 +1,230 @@
+// Copyright 2025-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package appext
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"buf.build/go/app"
+)
+
+const (
+	instancePollInterval = 100 * time.Millisecond
+	// pidFileLockAttempts is the number of attempts to lock the PID file, as
+	// GetInstanceStatus briefly holds a shared lock on it.
+	pidFileLockAttempts      = 5
+	pidFileLockRetryInterval = 10 * time.Millisecond
+)
+
+// pidFileContextKey is the context key for the *pidFile of the running command.
+type pidFileContextKey struct{}
+
+// pidFile is the PID file of the running command, written by Listen.
+//
+// The lock of the FileSystem on the PID file is held until the PID file is removed, so
+// that an instance is running if and only if the lock is held.
+type pidFile struct {
+	container NameContainer
+	lock      sync.Mutex
+	// filePath is the path of the PID file, or empty if it has not been written.
+	filePath string
+	// unlock releases the lock on the PID file, or is nil if it has not been written.
+	unlock func() error
+}
+
+func newPIDFile(container NameContainer) *pidFile {
+	return &pidFile{
+		container: container,
+	}
+}
+
+// create locks and writes the PID file, unless it was already written by this process.
+//
+// Returns an error if the PID file is locked by a running instance. Locking is attempted
+// pidFileLockAttempts times, so that an instance is not reported as running while the
+// status of the instance is being checked.
+func (p *pidFile) create() error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.filePath != "" {
+		return nil
+	}
+	filePath, err := getPIDFilePath(p.container)
+	if err != nil {
+		return err
+	}
+	fileSystem := p.container.FileSystem()
+	if err := fileSystem.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return err
+	}
+	var unlock func() error
+	for attempt := 1; ; attempt++ {
+		unlock, err = fileSystem.TryLock(filePath, false)
+		if !errors.Is(err, app.ErrLocked) || attempt == pidFileLockAttempts {
+			break
+		}
+		<-p.container.Clock().After(pidFileLockRetryInterval)
+	}
+	if err != nil {
+		if !errors.Is(err, app.ErrLocked) {
+			return err
+		}
+		if holder, ok := readFileLockHolder(fileSystem, filePath); ok {
+			return fmt.Errorf("%s is already running as process %d: PID file %s is locked", p.container.AppName(), holder.PID, filePath)
+		}
+		return fmt.Errorf("%s is already running: PID file %s is locked", p.container.AppName(), filePath)
+	}
+	// The PID file of an instance that exited without removing it is overwritten.
+	if err := fileSystem.WriteFile(filePath, []byte(strconv.Itoa(os.Getpid())+"\n"), 0644); err != nil {
+		return errors.Join(err, fileSystem.Remove(filePath), unlock())
+	}
+	p.filePath = filePath
+	p.unlock = unlock
+	return nil
+}
+
+// remove removes the PID file if it was written, and then releases the lock on it.
+func (p *pidFile) remove() error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.filePath == "" {
+		return nil
+	}
+	filePath := p.filePath
+	unlock := p.unlock
+	p.filePath = ""
+	p.unlock = nil
+	err := p.container.FileSystem().Remove(filePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		err = nil
+	}
+	return errors.Join(err, unlock())
+}
+
+func contextWithPIDFile(ctx context.Context, pidFile *pidFile) context.Context {
+	return context.WithValue(ctx, pidFileContextKey{}, pidFile)
+}
+
+// getPIDFileForContext returns the *pidFile of the running command, or nil if
+// BuilderWithPIDFile was not used.
+func getPIDFileForContext(ctx context.Context) *pidFile {
+	pidFile, _ := ctx.Value(pidFileContextKey{}).(*pidFile)
+	return pidFile
+}
+
+func getInstanceStatus(container NameContainer) (InstanceStatus, error) {
+	filePath, err := getPIDFilePath(container)
+	if err != nil {
+		return InstanceStatus{}, err
+	}
+	instanceStatus := InstanceStatus{
+		PIDFilePath: filePath,
+	}
+	fileSystem := container.FileSystem()
+	if _, err := fileSystem.Stat(filePath); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return instanceStatus, nil
+		}
+		return InstanceStatus{}, err
+	}
+	if holder, ok := readFileLockHolder(fileSystem, filePath); ok {
+		instanceStatus.PID = holder.PID
+	}
+	running, err := isPIDFileLocked(fileSystem, filePath)
+	if err != nil {
+		return InstanceStatus{}, err
+	}
+	instanceStatus.Running = running
+	return instanceStatus, nil
+}
+
+func stopInstance(ctx context.Context, container NameContainer) error {
+	instanceStatus, err := getInstanceStatus(container)
+	if err != nil {
+		return err
+	}
+	if !instanceStatus.Running {
+		return fmt.Errorf("%s is not running", container.AppName())
+	}
+	if instanceStatus.PID == 0 {
+		return fmt.Errorf("%s is running, but its PID file %s cannot be read", container.AppName(), instanceStatus.PIDFilePath)
+	}
+	if err := terminateProcess(instanceStatus.PID); err != nil {
+		return fmt.Errorf("could not stop process %d: %w", instanceStatus.PID, err)
+	}
+	// The lock on the PID file is released by the operating system when the process exits.
+	for {
+		running, err := isPIDFileLocked(container.FileSystem(), instanceStatus.PIDFilePath)
+		if err != nil {
+			return err
+		}
+		if !running {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("waiting for process %d to exit: %w", instanceStatus.PID, ctx.Err())
+		case <-container.Clock().After(instancePollInterval):
+		}
+	}
+}
+
+// isPIDFileLocked returns true if the PID file is locked by a running instance.
+//
+// The PID file is probed with a shared lock, so that concurrent probes do not conflict.
+// The PID file is never removed, as an instance may be starting, and an empty PID file
+// that is left behind if the instance removed it in the meantime is overwritten by the
+// next instance.
+func isPIDFileLocked(fileSystem app.FileSystem, filePath string) (bool, error) {
+	// The instance removed the PID file while exiting. This is checked first, as TryLock
+	// creates the PID file.
+	if _, err := fileSystem.Stat(filePath); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+	unlock, err := fileSystem.TryLock(filePath, true)
+	if err != nil {
+		if errors.Is(err, app.ErrLocked) {
+			return true, nil
+		}
+		if errors.Is(err, fs.ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+	return false, unlock()
+}
+
+// getPIDFilePath returns RuntimeDirPath/app-name.pid, falling back to DataDirPath.
+func getPIDFilePath(container NameContainer) (string, error) {
+	dirPath := container.RuntimeDirPath()
+	if dirPath == "" {
+		dirPath = container.DataDirPath()
+	}
+	if dirPath == "" {
+		return "", fmt.Errorf("no runtime or data directory for %s PID file", container.AppName())
+	}
+	return filepath.Join(dirPath, container.AppName()+".pid"), nil
+}
@@ -0,0 +1,186 @@
+// Copyright 2025-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package appext
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"buf.build/go/app"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuilderPIDFile(t *testing.T) {
+	t.Parallel()
+	container := app.NewContainer(map[string]string{"FOO_BAR_DATA_DIR": "data"}, nil, nil, nil)
+	pidFilePath := filepath.Join("data", "foo-bar.pid")
+	fileSystem := container.FileSystem()
+	runFunc := NewBuilder("foo-bar", BuilderWithPIDFile()).NewRunFunc(
+		func(ctx context.Context, container Container) error {
+			// The PID file is only written by Listen.
+			instanceStatus, err := GetInstanceStatus(container)
+			require.NoError(t, err)
+			assert.Equal(t, InstanceStatus{PIDFilePath: pidFilePath}, instanceStatus)
+			listener, err := Listen(ctx, container, 0)
+			require.NoError(t, err)
+			defer func() { assert.NoError(t, listener.Close()) }()
+			data, err := fileSystem.ReadFile(pidFilePath)
+			require.NoError(t, err)
+			assert.Equal(t, strconv.Itoa(os.Getpid())+"\n", string(data))
+			instanceStatus, err = GetInstanceStatus(container)
+			require.NoError(t, err)
+			assert.Equal(t, InstanceStatus{PIDFilePath: pidFilePath, PID: os.Getpid(), Running: true}, instanceStatus)
+			// Listening again within the same command does not conflict with the PID file.
+			listener2, err := Listen(ctx, container, 0)
+			require.NoError(t, err)
+			return listener2.Close()
+		},
+	)
+	require.NoError(t, runFunc(t.Context(), container))
+	_, err := fileSystem.Stat(pidFilePath)
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestBuilderPIDFileRunning(t *testing.T) {
+	t.Parallel()
+	container := app.NewContainer(map[string]string{"FOO_BAR_RUNTIME_DIR": "run", "FOO_BAR_DATA_DIR": "data"}, nil, nil, nil)
+	pidFilePath := filepath.Join("run", "foo-bar.pid")
+	fileSystem := container.FileSystem()
+	require.NoError(t, fileSystem.MkdirAll("run", 0755))
+	unlock, err := fileSystem.TryLock(pidFilePath, false)
+	require.NoError(t, err)
+	defer func() { assert.NoError(t, unlock()) }()
+	require.NoError(t, fileSystem.WriteFile(pidFilePath, []byte(strconv.Itoa(os.Getpid())+"\n"), 0644))
+	runFunc := NewBuilder("foo-bar", BuilderWithPIDFile()).NewRunFunc(
+		func(ctx context.Context, container Container) error {
+			_, err := Listen(ctx, container, 0)
+			return err
+		},
+	)
+	err = runFunc(t.Context(), container)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "foo-bar is already running as process "+strconv.Itoa(os.Getpid()))
+	// The PID file of the running instance is not removed.
+	_, err = fileSystem.Stat(pidFilePath)
+	require.NoError(t, err)
+}
+
+func TestRunInstanceStatus(t *testing.T) {
+	t.Parallel()
+	stdout := bytes.NewBuffer(nil)
+	container := app.NewContainer(map[string]string{"FOO_BAR_DATA_DIR": "data"}, nil, stdout, nil)
+	runFunc := NewBuilder("foo-bar").NewRunFunc(runInstanceStatus)
+	require.NoError(t, runFunc(t.Context(), container))
+	assert.Equal(t, "foo-bar is not running\n", stdout.String())
+	stdout.Reset()
+	pidFilePath := filepath.Join("data", "foo-bar.pid")
+	require.NoError(t, container.FileSystem().MkdirAll("data", 0755))
+	require.NoError(t, container.FileSystem().WriteFile(pidFilePath, []byte(strconv.Itoa(os.Getpid())+"\n"), 0644))
+	// The instance is only running if the PID file is locked.
+	require.NoError(t, runFunc(t.Context(), container))
+	assert.Equal(t, "foo-bar is not running\n", stdout.String())
+	stdout.Reset()
+	unlock, err := container.FileSystem().TryLock(pidFilePath, false)
+	require.NoError(t, err)
+	require.NoError(t, runFunc(t.Context(), container))
+	assert.Equal(t, "foo-bar is running as process "+strconv.Itoa(os.Getpid())+"\n", stdout.String())
+	require.NoError(t, unlock())
+}
+
+func TestBuilderPIDFileUnlocked(t *testing.T) {
+	t.Parallel()
+	container := app.NewContainer(map[string]string{"FOO_BAR_DATA_DIR": "data"}, nil, nil, nil)
+	pidFilePath := filepath.Join("data", "foo-bar.pid")
+	fileSystem := container.FileSystem()
+	require.NoError(t, fileSystem.MkdirAll("data", 0755))
+	// The PID file of an instance that exited without removing it, whose PID was reused
+	// by this process, which must not be stopped.
+	require.NoError(t, fileSystem.WriteFile(pidFilePath, []byte(strconv.Itoa(os.Getpid())+"\n"), 0644))
+	nameContainer, err := NewNameContainer(container, "foo-bar")
+	require.NoError(t, err)
+	instanceStatus, err := GetInstanceStatus(nameContainer)
+	require.NoError(t, err)
+	assert.Equal(t, InstanceStatus{PIDFilePath: pidFilePath, PID: os.Getpid()}, instanceStatus)
+	require.ErrorContains(t, StopInstance(t.Context(), nameContainer), "foo-bar is not running")
+	runFunc := NewBuilder("foo-bar", BuilderWithPIDFile()).NewRunFunc(
+		func(ctx context.Context, container Container) error {
+			listener, err := Listen(ctx, container, 0)
+			require.NoError(t, err)
+			data, err := fileSystem.ReadFile(pidFilePath)
+			require.NoError(t, err)
+			assert.Equal(t, strconv.Itoa(os.Getpid())+"\n", string(data))
+			return listener.Close()
+		},
+	)
+	require.NoError(t, runFunc(t.Context(), container))
+	_, err = fileSystem.Stat(pidFilePath)
+	require.ErrorIs(t, err, os.ErrNotExist)
+	// Checking the status does not leave a PID file behind.
+	instanceStatus, err = GetInstanceStatus(nameContainer)
+	require.NoError(t, err)
+	assert.Equal(t, InstanceStatus{PIDFilePath: pidFilePath}, instanceStatus)
+}
+
+func TestPIDFileCreateWhileStatus(t *testing.T) {
+	t.Parallel()
+	clock := &testAfterFuncClock{Clock: app.NewFakeClock(time.Now())}
+	container, err := NewNameContainer(
+		app.NewContainerForClock(
+			app.NewContainer(map[string]string{"FOO_BAR_DATA_DIR": "data"}, nil, nil, nil),
+			clock,
+		),
+		"foo-bar",
+	)
+	require.NoError(t, err)
+	pidFilePath := filepath.Join("data", "foo-bar.pid")
+	fileSystem := container.FileSystem()
+	require.NoError(t, fileSystem.MkdirAll("data", 0755))
+	// The PID file of an instance that exited without removing it.
+	require.NoError(t, fileSystem.WriteFile(pidFilePath, []byte("1\n"), 0644))
+	// The shared lock of a concurrent status check, which ends while waiting to retry.
+	unlock, err := fileSystem.TryLock(pidFilePath, true)
+	require.NoError(t, err)
+	clock.afterFunc = func() { assert.NoError(t, unlock()) }
+	pidFile := newPIDFile(container)
+	require.NoError(t, pidFile.create())
+	instanceStatus, err := GetInstanceStatus(container)
+	require.NoError(t, err)
+	assert.Equal(t, InstanceStatus{PIDFilePath: pidFilePath, PID: os.Getpid(), Running: true}, instanceStatus)
+	require.NoError(t, pidFile.remove())
+	_, err = fileSystem.Stat(pidFilePath)
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+// testAfterFuncClock is a Clock that calls afterFunc once instead of waiting in After.
+type testAfterFuncClock struct {
+	app.Clock
+	afterFunc func()
+}
+
+func (c *testAfterFuncClock) After(time.Duration) <-chan time.Time {
+	if c.afterFunc != nil {
+		c.afterFunc()
+		c.afterFunc = nil
+	}
+	timeC := make(chan time.Time, 1)
+	timeC <- c.Now()
+	return timeC
+}
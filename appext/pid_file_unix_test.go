@@ -0,0 +1,55 @@
+// Copyright 2025-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Excluding js,wasm from the unix-like build tags, as programs cannot be run there.
+
+//go:build aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+
+package appext
+
+import (
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"buf.build/go/app"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStopInstance(t *testing.T) {
+	t.Parallel()
+	cmd := exec.Command("/bin/sh", "-c", "sleep 60")
+	require.NoError(t, cmd.Start())
+	// Reap the process once it exits, so that it is no longer running.
+	waitErrC := make(chan error, 1)
+	go func() { waitErrC <- cmd.Wait() }()
+	container, err := NewNameContainer(app.NewContainer(map[string]string{"FOO_BAR_RUNTIME_DIR": "run"}, nil, nil, nil), "foo-bar")
+	require.NoError(t, err)
+	pidFilePath := filepath.Join("run", "foo-bar.pid")
+	require.NoError(t, container.FileSystem().MkdirAll("run", 0755))
+	// The lock is held for the process, and released when it exits, as the operating
+	// system does for the locks of the process.
+	unlock, err := container.FileSystem().TryLock(pidFilePath, false)
+	require.NoError(t, err)
+	require.NoError(t, container.FileSystem().WriteFile(pidFilePath, []byte(strconv.Itoa(cmd.Process.Pid)+"\n"), 0644))
+	go func() {
+		err := <-waitErrC
+		assert.NoError(t, unlock())
+		waitErrC <- err
+	}()
+	require.NoError(t, StopInstance(t.Context(), container))
+	require.ErrorContains(t, <-waitErrC, "terminated")
+}
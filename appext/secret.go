@@ -0,0 +1,145 @@
+// Copyright 2025-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package appext
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"buf.build/go/app"
+)
+
+const (
+	// secretEnvInfix is prepended to the names of secrets in their environment variables,
+	// so that secrets do not share environment variables with directories, ports, and
+	// configuration keys.
+	secretEnvInfix      = "SECRET_"
+	secretFileEnvSuffix = "_FILE"
+)
+
+func readSecret(container NameContainer, name string) (string, error) {
+	if err := validateSecretName(name); err != nil {
+		return "", err
+	}
+	envKey := getSecretEnvKey(container.AppName(), name)
+	if secret := container.Env(envKey); secret != "" {
+		return trimSecret(secret), nil
+	}
+	if secretFilePath := container.Env(envKey + secretFileEnvSuffix); secretFilePath != "" {
+		// Permissions are not checked, as mounted secrets are commonly readable by others,
+		// and the path was given explicitly.
+		data, err := container.FileSystem().ReadFile(app.ResolvePath(container, secretFilePath))
+		if err != nil {
+			return "", fmt.Errorf("failed to read secret %s from $%s: %w", name, envKey+secretFileEnvSuffix, err)
+		}
+		return trimSecret(string(data)), nil
+	}
+	secretFilePath, err := getSecretFilePath(container, name)
+	if err != nil {
+		return "", err
+	}
+	// OK to use Stat instead of Lstat here
+	fileInfo, err := container.FileSystem().Stat(secretFilePath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", fmt.Errorf(
+				"secret %s not found, set $%s, set $%s, or write it to %s: %w",
+				name,
+				envKey,
+				envKey+secretFileEnvSuffix,
+				secretFilePath,
+				err,
+			)
+		}
+		return "", fmt.Errorf("failed to read secret at %s: %w", secretFilePath, err)
+	}
+	if err := checkSecretFileMode(secretFilePath, fileInfo.Mode()); err != nil {
+		return "", err
+	}
+	data, err := container.FileSystem().ReadFile(secretFilePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret at %s: %w", secretFilePath, err)
+	}
+	return trimSecret(string(data)), nil
+}
+
+func listSecrets(container NameContainer) ([]string, error) {
+	secretDirPath, err := getSecretDirPath(container)
+	if err != nil {
+		return nil, err
+	}
+	dirEntries, err := container.FileSystem().ReadDir(secretDirPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var names []string
+	for _, dirEntry := range dirEntries {
+		// This skips temporary files, which start with ".".
+		if dirEntry.IsDir() || validateSecretName(dirEntry.Name()) != nil {
+			continue
+		}
+		names = append(names, dirEntry.Name())
+	}
+	// ReadDir returns the entries sorted by name.
+	return names, nil
+}
+
+func getSecretFilePath(container NameContainer, name string) (string, error) {
+	if err := validateSecretName(name); err != nil {
+		return "", err
+	}
+	secretDirPath, err := getSecretDirPath(container)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(secretDirPath, name), nil
+}
+
+func getSecretDirPath(container NameContainer) (string, error) {
+	configDirPath := container.ConfigDirPath()
+	if configDirPath == "" {
+		return "", fmt.Errorf("no configuration directory for %s", container.AppName())
+	}
+	return filepath.Join(configDirPath, secretRelDirPath), nil
+}
+
+// getSecretEnvKey returns the environment variable key for the secret, APP_NAME_SECRET_NAME.
+func getSecretEnvKey(appName string, name string) string {
+	return getAppNameEnvPrefix(appName) + secretEnvInfix + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+// trimSecret trims trailing newlines, which editors and echo commonly add to secret files.
+func trimSecret(secret string) string {
+	return strings.TrimRight(secret, "\r\n")
+}
+
+func validateSecretName(name string) error {
+	if name == "" {
+		return errors.New("empty secret name")
+	}
+	for _, c := range name {
+		if !((c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') || c == '-' || c == '_') {
+			return fmt.Errorf("invalid secret name: %s", name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+// Copyright 2025-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package appext
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+
+	"buf.build/go/app"
+)
+
+// secretHelperEnvSuffix is outside of the APP_NAME_SECRET_ environment variables of
+// secrets, so that any secret name can be used.
+const secretHelperEnvSuffix = "SECRETS_HELPER"
+
+type fileSecretProvider struct {
+	container NameContainer
+}
+
+func newFileSecretProvider(container NameContainer) *fileSecretProvider {
+	return &fileSecretProvider{
+		container: container,
+	}
+}
+
+func (f *fileSecretProvider) GetSecret(_ context.Context, name string) (string, error) {
+	return ReadSecret(f.container, name)
+}
+
+func (f *fileSecretProvider) StoreSecret(_ context.Context, name string, secret string) error {
+	return WriteSecret(f.container, name, secret)
+}
+
+func (f *fileSecretProvider) EraseSecret(_ context.Context, name string) error {
+	if err := DeleteSecret(f.container, name); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+type helperSecretProvider struct {
+	container app.Container
+	helper    string
+}
+
+func newHelperSecretProvider(container app.Container, helper string) *helperSecretProvider {
+	return &helperSecretProvider{
+		container: container,
+		helper:    helper,
+	}
+}
+
+func (h *helperSecretProvider) GetSecret(ctx context.Context, name string) (string, error) {
+	stdout, err := h.run(ctx, "get", name)
+	if err != nil {
+		return "", err
+	}
+	values, err := parseSecretHelperOutput(stdout)
+	if err != nil {
+		return "", fmt.Errorf("secret helper %q: %w", h.helper, err)
+	}
+	secret, ok := values["secret"]
+	if !ok {
+		return "", fmt.Errorf("secret %s not found by secret helper %q: %w", name, h.helper, fs.ErrNotExist)
+	}
+	return secret, nil
+}
+
+func (h *helperSecretProvider) StoreSecret(ctx context.Context, name string, secret string) error {
+	if strings.ContainsAny(secret, "\r\n\x00") {
+		return errors.New("secrets stored by a secret helper may not contain newlines or NUL characters")
+	}
+	_, err := h.run(ctx, "store", name, "secret="+secret)
+	return err
+}
+
+func (h *helperSecretProvider) EraseSecret(ctx context.Context, name string) error {
+	_, err := h.run(ctx, "erase", name)
+	return err
+}
+
+// run runs the helper for the operation, returning its stdout.
+//
+// The name line is written to stdin, followed by the additional lines.
+func (h *helperSecretProvider) run(ctx context.Context, operation string, name string, lines ...string) ([]byte, error) {
+	if err := validateSecretName(name); err != nil {
+		return nil, err
+	}
+	helperFields := strings.Fields(h.helper)
+	if len(helperFields) == 0 {
+		return nil, errors.New("empty secret helper")
+	}
+	stdin := bytes.NewBuffer(nil)
+	for _, line := range append([]string{"name=" + name}, lines...) {
+		_, _ = stdin.WriteString(line + "\n")
+	}
+	stdout := bytes.NewBuffer(nil)
+	stderr := bytes.NewBuffer(nil)
+	if err := app.Exec(
+		ctx,
+		h.container,
+		helperFields[0],
+		append(helperFields[1:], operation),
+		app.ExecWithStdin(stdin),
+		app.ExecWithStdout(stdout),
+		app.ExecWithStderr(stderr),
+	); err != nil {
+		if stderrString := strings.TrimSpace(stderr.String()); stderrString != "" {
+			return nil, fmt.Errorf("secret helper %q failed to %s secret %s: %w: %s", h.helper, operation, name, err, stderrString)
+		}
+		return nil, fmt.Errorf("secret helper %q failed to %s secret %s: %w", h.helper, operation, name, err)
+	}
+	return stdout.Bytes(), nil
+}
+
+// parseSecretHelperOutput parses the key=value lines written by a secret helper.
+func parseSecretHelperOutput(data []byte) (map[string]string, error) {
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if line == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			// The line is not printed, as it may contain the secret.
+			return nil, fmt.Errorf("invalid output on line %d, expected key=value", lineNumber)
+		}
+		values[key] = value
+	}
+	return values, scanner.Err()
+}
@@ -0,0 +1,109 @@
+// Copyright 2025-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package appext
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io/fs"
+	"slices"
+	"strings"
+	"testing"
+
+	"buf.build/go/app"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHelperSecretProvider(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	secrets := make(map[string]string)
+	runner := app.NewFakeRunner(
+		func(_ context.Context, command *app.ExecCommand) error {
+			if slices.Contains(command.Args, "--fail") {
+				_, _ = fmt.Fprintln(command.Stderr, "keychain is locked")
+				return app.NewError(1, "failed")
+			}
+			values := make(map[string]string)
+			scanner := bufio.NewScanner(command.Stdin)
+			for scanner.Scan() {
+				key, value, _ := strings.Cut(scanner.Text(), "=")
+				values[key] = value
+			}
+			switch operation := command.Args[len(command.Args)-1]; operation {
+			case "get":
+				if secret, ok := secrets[values["name"]]; ok {
+					_, _ = fmt.Fprintf(command.Stdout, "ignored=true\nsecret=%s\n", secret)
+				}
+			case "store":
+				secrets[values["name"]] = values["secret"]
+			case "erase":
+				delete(secrets, values["name"])
+			default:
+				return fmt.Errorf("unknown operation %s", operation)
+			}
+			return nil
+		},
+	)
+	container := testNewSecretContainer(t, map[string]string{"FOO_BAR_SECRETS_HELPER": "keychain-helper --service foo-bar"})
+	container, err := NewNameContainer(app.NewContainerForRunner(container, runner), "foo-bar")
+	require.NoError(t, err)
+	secretProvider := NewSecretProvider(container)
+
+	_, err = secretProvider.GetSecret(ctx, "api-token")
+	require.ErrorIs(t, err, fs.ErrNotExist)
+	require.NoError(t, secretProvider.StoreSecret(ctx, "api-token", "hunter2"))
+	require.Equal(t, map[string]string{"api-token": "hunter2"}, secrets)
+	secret, err := secretProvider.GetSecret(ctx, "api-token")
+	require.NoError(t, err)
+	require.Equal(t, "hunter2", secret)
+	require.NoError(t, secretProvider.EraseSecret(ctx, "api-token"))
+	require.Empty(t, secrets)
+	require.Error(t, secretProvider.StoreSecret(ctx, "api-token", "multi\nline"))
+	require.Error(t, secretProvider.StoreSecret(ctx, "../api-token", "hunter2"))
+
+	commands := runner.Commands()
+	require.Len(t, commands, 4)
+	require.Equal(t, "keychain-helper", commands[0].Name)
+	require.Equal(t, []string{"--service", "foo-bar", "get"}, commands[0].Args)
+
+	secretProvider = NewHelperSecretProvider(container, "keychain-helper --fail")
+	_, err = secretProvider.GetSecret(ctx, "api-token")
+	require.ErrorContains(t, err, "keychain is locked")
+}
+
+func TestFileSecretProvider(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	// The secret named helper does not select a secret helper.
+	container := testNewSecretContainer(t, map[string]string{"FOO_BAR_SECRET_HELPER": "swordfish"})
+	secretProvider := NewSecretProvider(container)
+	secret, err := secretProvider.GetSecret(ctx, "helper")
+	require.NoError(t, err)
+	require.Equal(t, "swordfish", secret)
+	require.NoError(t, secretProvider.StoreSecret(ctx, "api-token", "hunter2"))
+	secret, err = ReadSecret(container, "api-token")
+	require.NoError(t, err)
+	require.Equal(t, "hunter2", secret)
+	secret, err = secretProvider.GetSecret(ctx, "api-token")
+	require.NoError(t, err)
+	require.Equal(t, "hunter2", secret)
+	require.NoError(t, secretProvider.EraseSecret(ctx, "api-token"))
+	_, err = secretProvider.GetSecret(ctx, "api-token")
+	require.ErrorIs(t, err, fs.ErrNotExist)
+	// Erasing is idempotent, as with secret helpers.
+	require.NoError(t, secretProvider.EraseSecret(ctx, "api-token"))
+}
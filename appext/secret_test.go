@@ -0,0 +1,109 @@
+// Copyright 2025-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package appext
+
+import (
+	"io/fs"
+	"path/filepath"
+	"testing"
+
+	"buf.build/go/app"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecrets(t *testing.T) {
+	t.Parallel()
+	container := testNewSecretContainer(t, nil)
+	names, err := ListSecrets(container)
+	require.NoError(t, err)
+	require.Empty(t, names)
+	_, err = ReadSecret(container, "api-token")
+	require.ErrorIs(t, err, fs.ErrNotExist)
+	require.ErrorContains(t, err, "FOO_BAR_SECRET_API_TOKEN")
+
+	require.NoError(t, WriteSecret(container, "api-token", "hunter2\n"))
+	require.NoError(t, WriteSecret(container, "password", "swordfish"))
+	fileInfo, err := container.FileSystem().Stat(filepath.Join("config", "secrets", "api-token"))
+	require.NoError(t, err)
+	require.Equal(t, fs.FileMode(0600), fileInfo.Mode().Perm())
+	secret, err := ReadSecret(container, "api-token")
+	require.NoError(t, err)
+	require.Equal(t, "hunter2", secret)
+	names, err = ListSecrets(container)
+	require.NoError(t, err)
+	require.Equal(t, []string{"api-token", "password"}, names)
+
+	require.NoError(t, DeleteSecret(container, "api-token"))
+	require.ErrorIs(t, DeleteSecret(container, "api-token"), fs.ErrNotExist)
+	names, err = ListSecrets(container)
+	require.NoError(t, err)
+	require.Equal(t, []string{"password"}, names)
+
+	for _, name := range []string{"", "../config", "foo/bar", ".hidden"} {
+		_, err := ReadSecret(container, name)
+		require.Error(t, err, name)
+		require.Error(t, WriteSecret(container, name, "secret"), name)
+	}
+}
+
+func TestReadSecretEnv(t *testing.T) {
+	t.Parallel()
+	container := testNewSecretContainer(
+		t,
+		map[string]string{
+			"FOO_BAR_SECRET_API_TOKEN":     "from-env",
+			"FOO_BAR_SECRET_PASSWORD_FILE": "password.txt",
+		},
+	)
+	require.NoError(t, container.FileSystem().WriteFile(filepath.Join("work", "password.txt"), []byte("from-file\r\n"), 0644))
+	require.NoError(t, WriteSecret(container, "api-token", "from-dir"))
+	require.NoError(t, WriteSecret(container, "password", "from-dir"))
+	secret, err := ReadSecret(container, "api-token")
+	require.NoError(t, err)
+	require.Equal(t, "from-env", secret)
+	secret, err = ReadSecret(container, "password")
+	require.NoError(t, err)
+	require.Equal(t, "from-file", secret)
+}
+
+func TestReadSecretEnvNamespace(t *testing.T) {
+	t.Parallel()
+	container := testNewSecretContainer(
+		t,
+		map[string]string{
+			"FOO_BAR_PORT":     "8080",
+			"FOO_BAR_TOKEN":    "config-token",
+			"FOO_BAR_KEY_FILE": "key.txt",
+		},
+	)
+	require.NoError(t, container.FileSystem().WriteFile(filepath.Join("work", "key.txt"), []byte("from-file"), 0644))
+	// The other environment variables of the application are not secrets.
+	for _, name := range []string{"port", "config-dir", "token", "key"} {
+		_, err := ReadSecret(container, name)
+		require.ErrorIs(t, err, fs.ErrNotExist, name)
+	}
+}
+
+func testNewSecretContainer(t *testing.T, env map[string]string) NameContainer {
+	allEnv := map[string]string{"FOO_BAR_CONFIG_DIR": "config"}
+	for key, value := range env {
+		allEnv[key] = value
+	}
+	baseContainer := app.NewContainerForWorkDir(app.NewContainer(allEnv, nil, nil, nil, "test"), "work")
+	require.NoError(t, baseContainer.FileSystem().MkdirAll("work", 0755))
+	container, err := NewNameContainer(baseContainer, "foo-bar")
+	require.NoError(t, err)
+	return container
+}
@@ -0,0 +1,40 @@
+// Copyright 2025-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Matching the unix-like build tags in the Golang source i.e. https://github.com/golang/go/blob/912f0750472dd4f674b69ca1616bfaf377af1805/src/os/file_unix.go#L6
+
+//go:build aix || darwin || dragonfly || freebsd || (js && wasm) || linux || netbsd || openbsd || solaris
+
+package appext
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadSecretPermissions(t *testing.T) {
+	t.Parallel()
+	container := testNewSecretContainer(t, nil)
+	secretDirPath := filepath.Join("config", "secrets")
+	require.NoError(t, container.FileSystem().MkdirAll(secretDirPath, 0700))
+	require.NoError(t, container.FileSystem().WriteFile(filepath.Join(secretDirPath, "api-token"), []byte("hunter2"), 0644))
+	_, err := ReadSecret(container, "api-token")
+	require.ErrorContains(t, err, "chmod 600")
+	require.NoError(t, WriteSecret(container, "api-token", "hunter2"))
+	secret, err := ReadSecret(container, "api-token")
+	require.NoError(t, err)
+	require.Equal(t, "hunter2", secret)
+}
@@ -0,0 +1,261 @@
+// Copyright 2025-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	bindEnvTagName          = "env"
+	bindEnvDefaultTagName   = "envDefault"
+	bindEnvPrefixTagName    = "envPrefix"
+	bindEnvSeparatorTagName = "envSeparator"
+	bindEnvRequiredOption   = "required"
+	bindEnvDefaultSeparator = ","
+)
+
+var (
+	errBindEnvUnsupportedType = errors.New("unsupported type")
+	errBindEnvRequired        = errors.New("required but not set")
+
+	durationType        = reflect.TypeFor[time.Duration]()
+	urlType             = reflect.TypeFor[url.URL]()
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
+type bindEnvOptions struct {
+	prefix string
+}
+
+func newBindEnvOptions() *bindEnvOptions {
+	return &bindEnvOptions{}
+}
+
+func bindEnv(container EnvContainer, value any, bindEnvOptions *bindEnvOptions) error {
+	reflectValue := reflect.ValueOf(value)
+	if reflectValue.Kind() != reflect.Pointer || reflectValue.IsNil() || reflectValue.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("expected a non-nil pointer to a struct, got %T", value)
+	}
+	var errs []error
+	bindEnvStruct(container, reflectValue.Elem(), bindEnvOptions.prefix, &errs)
+	return errors.Join(errs...)
+}
+
+// bindEnvStruct binds the fields of the struct, and returns true if any environment variable
+// was set for the struct or its nested structs.
+func bindEnvStruct(container EnvContainer, structValue reflect.Value, prefix string, errs *[]error) bool {
+	var bound bool
+	structType := structValue.Type()
+	for i := range structType.NumField() {
+		field := structType.Field(i)
+		if !field.IsExported() && !(field.Anonymous && field.Type.Kind() == reflect.Struct) {
+			continue
+		}
+		fieldValue := structValue.Field(i)
+		tag, hasTag := field.Tag.Lookup(bindEnvTagName)
+		if hasTag && !field.IsExported() {
+			continue
+		}
+		if !hasTag {
+			fieldPrefix, hasPrefix := field.Tag.Lookup(bindEnvPrefixTagName)
+			if !hasPrefix && !field.Anonymous {
+				continue
+			}
+			if fieldValue.Kind() == reflect.Pointer && fieldValue.Type().Elem().Kind() == reflect.Struct {
+				if fieldValue.IsNil() {
+					// A nil field is only allocated if any of its environment variables are set,
+					// so that it stays nil if it is not configured. Its required environment
+					// variables are then not required either.
+					elemValue := reflect.New(fieldValue.Type().Elem())
+					var elemErrs []error
+					if bindEnvStruct(container, elemValue.Elem(), prefix+fieldPrefix, &elemErrs) {
+						fieldValue.Set(elemValue)
+						bound = true
+						*errs = append(*errs, elemErrs...)
+						continue
+					}
+					for _, err := range elemErrs {
+						if !errors.Is(err, errBindEnvRequired) {
+							*errs = append(*errs, err)
+						}
+					}
+					continue
+				}
+				fieldValue = fieldValue.Elem()
+			}
+			if fieldValue.Kind() != reflect.Struct {
+				if hasPrefix {
+					*errs = append(*errs, fmt.Errorf("field %s has tag %s but is not a struct", field.Name, bindEnvPrefixTagName))
+				}
+				continue
+			}
+			if bindEnvStruct(container, fieldValue, prefix+fieldPrefix, errs) {
+				bound = true
+			}
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		if name == "" {
+			*errs = append(*errs, fmt.Errorf("field %s has an empty %s tag", field.Name, bindEnvTagName))
+			continue
+		}
+		var required bool
+		for option := range strings.SplitSeq(options, ",") {
+			switch option {
+			case "":
+			case bindEnvRequiredOption:
+				required = true
+			default:
+				*errs = append(*errs, fmt.Errorf("field %s has unknown %s tag option %q", field.Name, bindEnvTagName, option))
+			}
+		}
+		key := prefix + name
+		envValue := container.Env(key)
+		if envValue == "" {
+			if required {
+				*errs = append(*errs, fmt.Errorf("environment variable %s is %w", key, errBindEnvRequired))
+				continue
+			}
+			defaultValue, hasDefault := field.Tag.Lookup(bindEnvDefaultTagName)
+			if !hasDefault {
+				continue
+			}
+			if err := setEnvFieldValue(fieldValue, field, defaultValue); err != nil {
+				*errs = append(*errs, newBindEnvFieldError(field, err, fmt.Sprintf("invalid %s tag for field %s: expected %s", bindEnvDefaultTagName, field.Name, err)))
+			}
+			continue
+		}
+		bound = true
+		if err := setEnvFieldValue(fieldValue, field, envValue); err != nil {
+			// Do not print out the value as we don't want to mistakenly leak a secure environment variable
+			*errs = append(*errs, newBindEnvFieldError(field, err, newEnvValueError(key, err.Error()).Error()))
+		}
+	}
+	return bound
+}
+
+// setEnvFieldValue sets the value of the field.
+//
+// The returned error describes the expected value, and never contains the value itself.
+func setEnvFieldValue(fieldValue reflect.Value, field reflect.StructField, value string) error {
+	if fieldValue.Kind() == reflect.Pointer {
+		elemValue := reflect.New(fieldValue.Type().Elem())
+		if err := setEnvFieldValue(elemValue.Elem(), field, value); err != nil {
+			return err
+		}
+		fieldValue.Set(elemValue)
+		return nil
+	}
+	if fieldValue.Addr().Type().Implements(textUnmarshalerType) {
+		if err := fieldValue.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(value)); err != nil {
+			return fmt.Errorf("a valid %s", fieldValue.Type())
+		}
+		return nil
+	}
+	switch fieldValue.Type() {
+	case durationType:
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			return errors.New("a duration such as 1m30s")
+		}
+		fieldValue.SetInt(int64(parsed))
+		return nil
+	case urlType:
+		parsed, err := url.Parse(value)
+		if err != nil || !parsed.IsAbs() {
+			return errors.New("an absolute URL")
+		}
+		fieldValue.Set(reflect.ValueOf(*parsed))
+		return nil
+	}
+	switch fieldValue.Kind() {
+	case reflect.String:
+		fieldValue.SetString(value)
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return errors.New("a boolean")
+		}
+		fieldValue.SetBool(parsed)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		parsed, err := strconv.ParseInt(value, 10, fieldValue.Type().Bits())
+		if err != nil {
+			return newEnvNumExpected("an integer", err)
+		}
+		fieldValue.SetInt(parsed)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		parsed, err := strconv.ParseUint(value, 10, fieldValue.Type().Bits())
+		if err != nil {
+			return newEnvNumExpected("a non-negative integer", err)
+		}
+		fieldValue.SetUint(parsed)
+	case reflect.Float32, reflect.Float64:
+		parsed, err := strconv.ParseFloat(value, fieldValue.Type().Bits())
+		if err != nil {
+			return newEnvNumExpected("a number", err)
+		}
+		fieldValue.SetFloat(parsed)
+	case reflect.Slice:
+		separator, ok := field.Tag.Lookup(bindEnvSeparatorTagName)
+		if !ok {
+			separator = bindEnvDefaultSeparator
+		}
+		var elements []string
+		for element := range strings.SplitSeq(value, separator) {
+			if element = strings.TrimSpace(element); element != "" {
+				elements = append(elements, element)
+			}
+		}
+		sliceValue := reflect.MakeSlice(fieldValue.Type(), len(elements), len(elements))
+		for i, element := range elements {
+			if err := setEnvFieldValue(sliceValue.Index(i), field, element); err != nil {
+				if errors.Is(err, errBindEnvUnsupportedType) {
+					return err
+				}
+				return fmt.Errorf("a list of elements separated by %q, each %s", separator, err.Error())
+			}
+		}
+		fieldValue.Set(sliceValue)
+	default:
+		return fmt.Errorf("%w %s", errBindEnvUnsupportedType, fieldValue.Type())
+	}
+	return nil
+}
+
+// newBindEnvFieldError returns an error for a failure to set the field.
+//
+// Unsupported types are programming errors and are reported against the field, otherwise
+// the message is used.
+func newBindEnvFieldError(field reflect.StructField, err error, message string) error {
+	if errors.Is(err, errBindEnvUnsupportedType) {
+		return fmt.Errorf("field %s: %w", field.Name, err)
+	}
+	return errors.New(message)
+}
+
+func newEnvNumExpected(expected string, err error) error {
+	if errors.Is(err, strconv.ErrRange) {
+		return fmt.Errorf("%s in range", expected)
+	}
+	return errors.New(expected)
+}
@@ -0,0 +1,59 @@
+// Copyright 2025-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// clockTimeoutContext is a context that is canceled when the timeout has elapsed on a Clock
+// that is not the real clock.
+//
+// The deadline on the Clock is not reported by Deadline, as it is not a real time, and
+// consumers such as net.Dialer compare it against the real time. Deadline returns the
+// deadline of the parent context, if any.
+type clockTimeoutContext struct {
+	context.Context
+}
+
+func contextWithClockTimeout(ctx context.Context, clock Clock, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if _, ok := clock.(osClock); ok {
+		return context.WithTimeout(ctx, timeout)
+	}
+	cancelCtx, cancel := context.WithCancelCause(ctx)
+	timer := clock.NewTimer(timeout)
+	go func() {
+		select {
+		case <-timer.C():
+			cancel(context.DeadlineExceeded)
+		case <-cancelCtx.Done():
+			timer.Stop()
+		}
+	}()
+	clockTimeoutCtx := &clockTimeoutContext{
+		Context: cancelCtx,
+	}
+	return clockTimeoutCtx, func() { cancel(nil) }
+}
+
+func (c *clockTimeoutContext) Err() error {
+	err := c.Context.Err()
+	if errors.Is(err, context.Canceled) && errors.Is(context.Cause(c.Context), context.DeadlineExceeded) {
+		return context.DeadlineExceeded
+	}
+	return err
+}
@@ -20,6 +20,11 @@ type container struct {
 	StdoutContainer
 	StderrContainer
 	ArgContainer
+	FileSystemContainer
+	WorkDirContainer
+	ClockContainer
+	RunnerContainer
+	TerminalContainer
 }
 
 func newContainer(
@@ -28,12 +33,22 @@ func newContainer(
 	stdoutContainer StdoutContainer,
 	stderrContainer StderrContainer,
 	argContainer ArgContainer,
+	fileSystemContainer FileSystemContainer,
+	workDirContainer WorkDirContainer,
+	clockContainer ClockContainer,
+	runnerContainer RunnerContainer,
+	terminalContainer TerminalContainer,
 ) *container {
 	return &container{
-		EnvContainer:    envContainer,
-		StdinContainer:  stdinContainer,
-		StdoutContainer: stdoutContainer,
-		StderrContainer: stderrContainer,
-		ArgContainer:    argContainer,
+		EnvContainer:        envContainer,
+		StdinContainer:      stdinContainer,
+		StdoutContainer:     stdoutContainer,
+		StderrContainer:     stderrContainer,
+		ArgContainer:        argContainer,
+		FileSystemContainer: fileSystemContainer,
+		WorkDirContainer:    workDirContainer,
+		ClockContainer:      clockContainer,
+		RunnerContainer:     runnerContainer,
+		TerminalContainer:   terminalContainer,
 	}
 }
@@ -0,0 +1,163 @@
+// Copyright 2025-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const crashReportFileTimeFormat = "20060102T150405Z"
+
+func runFunc(
+	ctx context.Context,
+	container Container,
+	f func(context.Context, Container) error,
+	runOptions *runOptions,
+) (retErr error) {
+	if runOptions.recoverPanics {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				retErr = newPanicError(container, recovered, debug.Stack(), runOptions)
+			}
+		}()
+	}
+	return f(ctx, container)
+}
+
+func newPanicError(container Container, recovered any, stack []byte, runOptions *runOptions) error {
+	message := fmt.Sprintf("internal error: %v", recovered)
+	var crashDirPath string
+	if runOptions.getCrashDirPath != nil {
+		crashDirPath = runOptions.getCrashDirPath(container)
+	}
+	if crashDirPath == "" {
+		return NewError(ExitCodePanic, message)
+	}
+	crashReportFilePath, err := writeCrashReport(container, crashDirPath, recovered, stack, runOptions.version)
+	if err != nil {
+		return NewErrorf(ExitCodePanic, "%s\ncould not write crash report: %v", message, err)
+	}
+	return NewErrorf(
+		ExitCodePanic,
+		"%s\nA crash report was written to %s, please include it when reporting this issue.",
+		message,
+		crashReportFilePath,
+	)
+}
+
+func writeCrashReport(
+	container Container,
+	crashDirPath string,
+	recovered any,
+	stack []byte,
+	version string,
+) (string, error) {
+	now := container.Clock().Now()
+	if version == "" {
+		version = getBuildInfoVersion()
+	}
+	var envKeys []string
+	container.ForEachEnv(func(key string, _ string) {
+		envKeys = append(envKeys, key)
+	})
+	sort.Strings(envKeys)
+	quotedArgs := getRedactedArgs(Args(container))
+	for i, arg := range quotedArgs {
+		quotedArgs[i] = strconv.Quote(arg)
+	}
+	var builder strings.Builder
+	_, _ = fmt.Fprintf(&builder, "panic: %v\n\n", recovered)
+	_, _ = fmt.Fprintf(&builder, "time: %s\n", now.UTC().Format(time.RFC3339))
+	_, _ = fmt.Fprintf(&builder, "version: %s\n", version)
+	_, _ = fmt.Fprintf(&builder, "go: %s %s/%s\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	_, _ = fmt.Fprintf(&builder, "args: %s\n", strings.Join(quotedArgs, " "))
+	_, _ = fmt.Fprintf(&builder, "env (values redacted): %s\n\n", strings.Join(envKeys, " "))
+	_, _ = builder.Write(stack)
+
+	fileSystem := container.FileSystem()
+	if err := fileSystem.MkdirAll(crashDirPath, 0755); err != nil {
+		return "", err
+	}
+	// Multiple crashes can happen within the same second, so the file is created exclusively,
+	// adding a counter to the file name until a file that does not exist is found.
+	baseName := fmt.Sprintf("crash-%s-%d", now.UTC().Format(crashReportFileTimeFormat), os.Getpid())
+	for i := 0; ; i++ {
+		crashReportFilePath := filepath.Join(crashDirPath, baseName+".txt")
+		if i > 0 {
+			crashReportFilePath = filepath.Join(crashDirPath, fmt.Sprintf("%s-%d.txt", baseName, i))
+		}
+		file, err := fileSystem.OpenFile(crashReportFilePath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err != nil {
+			if errors.Is(err, fs.ErrExist) {
+				continue
+			}
+			return "", err
+		}
+		_, err = file.Write([]byte(builder.String()))
+		if err := errors.Join(err, file.Close()); err != nil {
+			return "", err
+		}
+		return crashReportFilePath, nil
+	}
+}
+
+// getRedactedArgs returns a copy of the args with the values of flags that match
+// defaultRedactedEnvKeyPatterns redacted.
+//
+// Flag names are matched with dashes replaced by underscores, for example --api-key matches
+// *_KEY. Both --flag=value and --flag value are redacted, as it is not known which flags
+// take a value.
+func getRedactedArgs(args []string) []string {
+	redactedArgs := slices.Clone(args)
+	for i := 0; i < len(redactedArgs); i++ {
+		arg := redactedArgs[i]
+		if !strings.HasPrefix(arg, "-") {
+			continue
+		}
+		flag, _, hasValue := strings.Cut(arg, "=")
+		name := strings.ReplaceAll(strings.TrimLeft(flag, "-"), "-", "_")
+		if name == "" || !isRedactedKey(defaultRedactedEnvKeyPatterns, name) {
+			continue
+		}
+		if hasValue {
+			redactedArgs[i] = flag + "=" + redactedValue
+			continue
+		}
+		if i+1 < len(redactedArgs) && !strings.HasPrefix(redactedArgs[i+1], "-") {
+			redactedArgs[i+1] = redactedValue
+			i++
+		}
+	}
+	return redactedArgs
+}
+
+func getBuildInfoVersion() string {
+	if buildInfo, ok := debug.ReadBuildInfo(); ok && buildInfo.Main.Version != "" {
+		return buildInfo.Main.Version
+	}
+	return "unknown"
+}
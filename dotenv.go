@@ -0,0 +1,300 @@
+// Copyright 2025-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"fmt"
+	"strings"
+)
+
+// dotenvParser parses dotenv-formatted data.
+//
+// Errors never contain values, as we don't want to mistakenly leak a secure environment variable.
+type dotenvParser struct {
+	data string
+	pos  int
+	line int
+	base EnvContainer
+	// values are the values parsed so far, used for interpolation.
+	values map[string]string
+}
+
+func parseDotenv(base EnvContainer, data []byte) (map[string]string, error) {
+	parser := &dotenvParser{
+		data:   strings.ReplaceAll(string(data), "\r\n", "\n"),
+		line:   1,
+		base:   base,
+		values: make(map[string]string),
+	}
+	if err := parser.parse(); err != nil {
+		return nil, err
+	}
+	return parser.values, nil
+}
+
+func (p *dotenvParser) parse() error {
+	for {
+		p.skipWhitespace(true)
+		if p.done() {
+			return nil
+		}
+		if p.peek() == '#' {
+			p.skipLine()
+			continue
+		}
+		if err := p.parseAssignment(); err != nil {
+			return err
+		}
+	}
+}
+
+func (p *dotenvParser) parseAssignment() error {
+	key := p.parseKey()
+	if key == "export" && !p.done() && (p.peek() == ' ' || p.peek() == '\t') {
+		p.skipWhitespace(false)
+		key = p.parseKey()
+	}
+	if key == "" {
+		return p.newError("expected a variable name")
+	}
+	p.skipWhitespace(false)
+	if p.done() || p.peek() != '=' {
+		return p.newError(fmt.Sprintf("expected = after variable name %s", key))
+	}
+	p.pos++
+	p.skipWhitespace(false)
+	var value string
+	var err error
+	switch {
+	case p.done():
+	case p.peek() == '\'':
+		value, err = p.parseSingleQuotedValue()
+	case p.peek() == '"':
+		value, err = p.parseDoubleQuotedValue()
+	default:
+		value, err = p.parseUnquotedValue()
+	}
+	if err != nil {
+		return fmt.Errorf("%w for variable %s", err, key)
+	}
+	p.values[key] = value
+	return nil
+}
+
+func (p *dotenvParser) parseKey() string {
+	start := p.pos
+	for !p.done() && isDotenvKeyChar(p.peek(), p.pos == start) {
+		p.pos++
+	}
+	return p.data[start:p.pos]
+}
+
+func (p *dotenvParser) parseSingleQuotedValue() (string, error) {
+	startLine := p.line
+	p.pos++
+	end := strings.IndexByte(p.data[p.pos:], '\'')
+	if end < 0 {
+		return "", newDotenvError(startLine, "unterminated single-quoted value")
+	}
+	value := p.data[p.pos : p.pos+end]
+	p.line += strings.Count(value, "\n")
+	p.pos += end + 1
+	return value, p.parseEndOfLine()
+}
+
+func (p *dotenvParser) parseDoubleQuotedValue() (string, error) {
+	startLine := p.line
+	p.pos++
+	var builder strings.Builder
+	for {
+		if p.done() {
+			return "", newDotenvError(startLine, "unterminated double-quoted value")
+		}
+		c := p.peek()
+		switch c {
+		case '"':
+			p.pos++
+			return builder.String(), p.parseEndOfLine()
+		case '\\':
+			p.pos++
+			if p.done() {
+				return "", newDotenvError(startLine, "unterminated double-quoted value")
+			}
+			switch escaped := p.peek(); escaped {
+			case 'n':
+				builder.WriteByte('\n')
+			case 'r':
+				builder.WriteByte('\r')
+			case 't':
+				builder.WriteByte('\t')
+			case '\\', '"', '$':
+				builder.WriteByte(escaped)
+			default:
+				builder.WriteByte('\\')
+				builder.WriteByte(escaped)
+			}
+			p.pos++
+		case '$':
+			expanded, next, err := p.expand(p.data, p.pos)
+			if err != nil {
+				return "", err
+			}
+			builder.WriteString(expanded)
+			p.pos = next
+		default:
+			if c == '\n' {
+				p.line++
+			}
+			builder.WriteByte(c)
+			p.pos++
+		}
+	}
+}
+
+func (p *dotenvParser) parseUnquotedValue() (string, error) {
+	start := p.pos
+	p.skipLine()
+	raw := p.data[start:p.pos]
+	// An inline comment must be preceded by whitespace, so that values such as URL fragments are preserved.
+	for i := 1; i < len(raw); i++ {
+		if raw[i] == '#' && (raw[i-1] == ' ' || raw[i-1] == '\t') {
+			raw = raw[:i]
+			break
+		}
+	}
+	raw = strings.TrimSpace(raw)
+	var builder strings.Builder
+	for i := 0; i < len(raw); {
+		if raw[i] != '$' {
+			builder.WriteByte(raw[i])
+			i++
+			continue
+		}
+		expanded, next, err := p.expand(raw, i)
+		if err != nil {
+			return "", err
+		}
+		builder.WriteString(expanded)
+		i = next
+	}
+	return builder.String(), nil
+}
+
+// expand expands the variable reference starting with the $ at s[i].
+//
+// Supported forms are $VAR, ${VAR}, and ${VAR:-default}. A $ not followed by a variable
+// reference is returned as is. Returns the expanded value and the index after the reference.
+func (p *dotenvParser) expand(s string, i int) (string, int, error) {
+	i++
+	if i < len(s) && s[i] == '{' {
+		end := strings.IndexByte(s[i:], '}')
+		if end < 0 {
+			return "", 0, p.newError("unterminated variable reference")
+		}
+		name, defaultValue, hasDefault := strings.Cut(s[i+1:i+end], ":-")
+		if name == "" || !isDotenvKey(name) {
+			return "", 0, p.newError("invalid variable reference")
+		}
+		value := p.lookup(name)
+		if value == "" && hasDefault {
+			value = defaultValue
+		}
+		return value, i + end + 1, nil
+	}
+	start := i
+	for i < len(s) && isDotenvKeyChar(s[i], i == start) {
+		i++
+	}
+	if i == start {
+		return "$", i, nil
+	}
+	return p.lookup(s[start:i]), i, nil
+}
+
+// lookup looks up the value for interpolation.
+//
+// The base takes precedence, matching the precedence of the resulting EnvContainer.
+func (p *dotenvParser) lookup(name string) string {
+	if value := p.base.Env(name); value != "" {
+		return value
+	}
+	return p.values[name]
+}
+
+// parseEndOfLine parses the remainder of the line after a quoted value, which may
+// only contain whitespace and a comment.
+func (p *dotenvParser) parseEndOfLine() error {
+	p.skipWhitespace(false)
+	if p.done() || p.peek() == '\n' {
+		return nil
+	}
+	if p.peek() == '#' {
+		p.skipLine()
+		return nil
+	}
+	return p.newError("unexpected character after quoted value")
+}
+
+func (p *dotenvParser) skipWhitespace(newlines bool) {
+	for !p.done() {
+		switch p.peek() {
+		case ' ', '\t', '\r':
+		case '\n':
+			if !newlines {
+				return
+			}
+			p.line++
+		default:
+			return
+		}
+		p.pos++
+	}
+}
+
+func (p *dotenvParser) skipLine() {
+	for !p.done() && p.peek() != '\n' {
+		p.pos++
+	}
+}
+
+func (p *dotenvParser) peek() byte {
+	return p.data[p.pos]
+}
+
+func (p *dotenvParser) done() bool {
+	return p.pos >= len(p.data)
+}
+
+func (p *dotenvParser) newError(message string) error {
+	return newDotenvError(p.line, message)
+}
+
+func newDotenvError(line int, message string) error {
+	return fmt.Errorf("dotenv line %d: %s", line, message)
+}
+
+func isDotenvKey(s string) bool {
+	for i := range len(s) {
+		if !isDotenvKeyChar(s[i], i == 0) {
+			return false
+		}
+	}
+	return true
+}
+
+func isDotenvKeyChar(c byte, first bool) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c == '_' ||
+		(!first && ((c >= '0' && c <= '9') || c == '.'))
+}
@@ -16,6 +16,8 @@ package app
 
 import (
 	"errors"
+	"fmt"
+	"strconv"
 	"strings"
 )
 
@@ -72,3 +74,16 @@ func (e *envContainer) ForEachEnv(f func(string, string)) {
 func (e *envContainer) Size() int {
 	return len(e.variables)
 }
+
+func newEnvValueError(key string, expected string) error {
+	// Do not print out the value as we don't want to mistakenly leak a secure environment variable
+	return fmt.Errorf("invalid value for environment variable %s: expected %s", key, expected)
+}
+
+func newEnvNumError(key string, expected string, err error) error {
+	// Do not wrap err as *strconv.NumError contains the value
+	if errors.Is(err, strconv.ErrRange) {
+		return fmt.Errorf("invalid value for environment variable %s: value out of range", key)
+	}
+	return newEnvValueError(key, expected)
+}
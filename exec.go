@@ -0,0 +1,83 @@
+// Copyright 2025-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+)
+
+type execOptions struct {
+	envOverrides map[string]string
+	dirPath      string
+	stdin        io.Reader
+	stdout       io.Writer
+	stderr       io.Writer
+}
+
+func newExecOptions() *execOptions {
+	return &execOptions{}
+}
+
+func execCommand(
+	ctx context.Context,
+	container Container,
+	name string,
+	args []string,
+	execOptions *execOptions,
+) error {
+	var envContainer EnvContainer = container
+	if len(execOptions.envOverrides) > 0 {
+		envContainer = NewEnvContainerWithOverrides(container, execOptions.envOverrides)
+	}
+	dirPath := container.WorkDirPath()
+	if execOptions.dirPath != "" {
+		dirPath = ResolvePath(container, execOptions.dirPath)
+	}
+	stdin := execOptions.stdin
+	if stdin == nil {
+		stdin = container.Stdin()
+	}
+	stdout := execOptions.stdout
+	if stdout == nil {
+		stdout = container.Stdout()
+	}
+	stderr := execOptions.stderr
+	if stderr == nil {
+		stderr = container.Stderr()
+	}
+	command := &ExecCommand{
+		Name:   name,
+		Args:   slices.Clone(args),
+		Env:    Environ(envContainer),
+		Dir:    dirPath,
+		Stdin:  stdin,
+		Stdout: stdout,
+		Stderr: stderr,
+	}
+	if err := container.Runner().Run(ctx, command); err != nil {
+		err = fmt.Errorf("%s: %w", name, err)
+		var exitCoder interface{ ExitCode() int }
+		// A negative exit code means the program was terminated by a signal.
+		if errors.As(err, &exitCoder) && exitCoder.ExitCode() > 0 {
+			return WrapError(exitCoder.ExitCode(), err)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+// Copyright 2025-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Excluding js,wasm from the unix-like build tags, as programs cannot be run there.
+
+//go:build aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+
+package app
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecOS(t *testing.T) {
+	t.Parallel()
+	stdout := bytes.NewBuffer(nil)
+	container := NewContainerForRunner(
+		NewContainerForWorkDir(
+			NewContainer(map[string]string{"FOO": "foo"}, nil, stdout, nil),
+			t.TempDir(),
+		),
+		NewRunnerForOS(),
+	)
+	err := Exec(context.Background(), container, "/bin/sh", []string{"-c", `echo "$FOO"; exit 3`})
+	assert.Equal(t, 3, GetExitCode(err))
+	assert.Equal(t, "foo\n", stdout.String())
+}
+
+func TestExecOSPath(t *testing.T) {
+	t.Parallel()
+	binDirPath := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(binDirPath, "foo"), []byte("#!/bin/sh\necho \"$0\"\n"), 0755))
+	stdout := bytes.NewBuffer(nil)
+	container := NewContainerForRunner(
+		NewContainerForWorkDir(
+			NewContainer(map[string]string{"PATH": "relative:" + binDirPath}, nil, stdout, nil),
+			t.TempDir(),
+		),
+		NewRunnerForOS(),
+	)
+	require.NoError(t, Exec(context.Background(), container, "foo", nil))
+	assert.Equal(t, filepath.Join(binDirPath, "foo")+"\n", stdout.String())
+	// The $PATH of the process is not used, even though it contains sh.
+	err := Exec(context.Background(), container, "sh", []string{"-c", "true"})
+	require.ErrorIs(t, err, exec.ErrNotFound)
+}
@@ -0,0 +1,134 @@
+// Copyright 2025-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+type fakeClock struct {
+	now    time.Time
+	timers []*fakeTimer
+	lock   sync.Mutex
+}
+
+func newFakeClock(now time.Time) *fakeClock {
+	return &fakeClock{
+		now: now,
+	}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	return c.NewTimer(d).C()
+}
+
+func (c *fakeClock) NewTimer(d time.Duration) Timer {
+	timer := &fakeTimer{
+		clock: c,
+		c:     make(chan time.Time, 1),
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.startTimer(timer, d)
+	return timer
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.now = c.now.Add(d)
+	// Fire timers in deadline order.
+	sort.SliceStable(
+		c.timers,
+		func(i int, j int) bool {
+			return c.timers[i].deadline.Before(c.timers[j].deadline)
+		},
+	)
+	var remainingTimers []*fakeTimer
+	for _, timer := range c.timers {
+		if timer.deadline.After(c.now) {
+			remainingTimers = append(remainingTimers, timer)
+			continue
+		}
+		timer.fire(c.now)
+	}
+	c.timers = remainingTimers
+}
+
+// startTimer starts the timer.
+//
+// Must be called with the lock held.
+func (c *fakeClock) startTimer(timer *fakeTimer, d time.Duration) {
+	timer.deadline = c.now.Add(d)
+	if d <= 0 {
+		timer.fire(c.now)
+		return
+	}
+	c.timers = append(c.timers, timer)
+}
+
+// stopTimer stops the timer, returning true if the timer was active.
+//
+// Must be called with the lock held.
+func (c *fakeClock) stopTimer(timer *fakeTimer) bool {
+	for i, activeTimer := range c.timers {
+		if activeTimer == timer {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+type fakeTimer struct {
+	clock    *fakeClock
+	c        chan time.Time
+	deadline time.Time
+}
+
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.lock.Lock()
+	defer t.clock.lock.Unlock()
+	return t.clock.stopTimer(t)
+}
+
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	t.clock.lock.Lock()
+	defer t.clock.lock.Unlock()
+	active := t.clock.stopTimer(t)
+	t.clock.startTimer(t, d)
+	return active
+}
+
+func (t *fakeTimer) fire(now time.Time) {
+	// Matches the behavior of time.Timer, where a value is dropped if the
+	// channel was not drained.
+	select {
+	case t.c <- now:
+	default:
+	}
+}
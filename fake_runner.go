@@ -0,0 +1,49 @@
+// Copyright 2025-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"context"
+	"slices"
+	"sync"
+)
+
+type fakeRunner struct {
+	run      func(context.Context, *ExecCommand) error
+	commands []*ExecCommand
+	lock     sync.Mutex
+}
+
+func newFakeRunner(run func(context.Context, *ExecCommand) error) *fakeRunner {
+	return &fakeRunner{
+		run: run,
+	}
+}
+
+func (f *fakeRunner) Run(ctx context.Context, command *ExecCommand) error {
+	f.lock.Lock()
+	f.commands = append(f.commands, command)
+	f.lock.Unlock()
+	if f.run == nil {
+		return nil
+	}
+	return f.run(ctx, command)
+}
+
+func (f *fakeRunner) Commands() []*ExecCommand {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return slices.Clone(f.commands)
+}
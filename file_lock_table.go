@@ -0,0 +1,89 @@
+// Copyright 2025-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"sync"
+)
+
+// fileLockTable tracks the locks held within the process, so that holders within the
+// process exclude each other regardless of the semantics of the locks of the operating
+// system, which for example are per handle for LockFileEx.
+type fileLockTable struct {
+	lock        sync.Mutex
+	pathToEntry map[string]*fileLockEntry
+}
+
+type fileLockEntry struct {
+	shared bool
+	// count is the number of holders.
+	count int
+	// release releases the underlying lock.
+	release func() error
+}
+
+func newFileLockTable() *fileLockTable {
+	return &fileLockTable{
+		pathToEntry: make(map[string]*fileLockEntry),
+	}
+}
+
+// tryLock acquires the lock on the path within the process, calling acquire to acquire
+// the underlying lock if there are no other holders within the process.
+//
+// The path must be absolute or cleaned, so that each file has one path.
+func (t *fileLockTable) tryLock(
+	name string,
+	path string,
+	shared bool,
+	acquire func() (func() error, error),
+) (func() error, error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	entry, ok := t.pathToEntry[path]
+	if ok {
+		if !entry.shared || !shared {
+			return nil, newPathError("lock", name, ErrLocked)
+		}
+	} else {
+		release, err := acquire()
+		if err != nil {
+			return nil, err
+		}
+		entry = &fileLockEntry{
+			shared:  shared,
+			release: release,
+		}
+		t.pathToEntry[path] = entry
+	}
+	entry.count++
+	var once sync.Once
+	var err error
+	return func() error {
+		once.Do(func() { err = t.unlock(path, entry) })
+		return err
+	}, nil
+}
+
+func (t *fileLockTable) unlock(path string, entry *fileLockEntry) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	entry.count--
+	if entry.count > 0 {
+		return nil
+	}
+	delete(t.pathToEntry, path)
+	return entry.release()
+}
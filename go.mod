@@ -4,9 +4,11 @@ go 1.25.0
 
 require (
 	buf.build/go/interrupt v1.1.0
+	github.com/BurntSushi/toml v1.6.0
 	github.com/spf13/cobra v1.9.1
 	github.com/spf13/pflag v1.0.6
 	github.com/stretchr/testify v1.10.0
+	golang.org/x/sys v0.47.0
 	gopkg.in/yaml.v3 v3.0.1
 )
 
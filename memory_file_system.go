@@ -0,0 +1,533 @@
+// Copyright 2025-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	errIsDirectory       = errors.New("is a directory")
+	errNotDirectory      = errors.New("not a directory")
+	errDirectoryNotEmpty = errors.New("directory not empty")
+	errNotOpenForRead    = errors.New("file not opened for reading")
+	errNotOpenForWrite   = errors.New("file not opened for writing")
+)
+
+type memoryFileSystem struct {
+	// nodes are keyed by cleaned path.
+	//
+	// Roots are not stored, see isMemoryRoot.
+	nodes map[string]*memoryNode
+	lock  sync.RWMutex
+	// fileLockTable holds the locks of TryLock.
+	fileLockTable *fileLockTable
+}
+
+func newMemoryFileSystem() *memoryFileSystem {
+	return &memoryFileSystem{
+		nodes:         make(map[string]*memoryNode),
+		fileLockTable: newFileLockTable(),
+	}
+}
+
+func (m *memoryFileSystem) OpenFile(name string, flag int, perm fs.FileMode) (File, error) {
+	path := filepath.Clean(name)
+	accessMode := flag & (os.O_RDONLY | os.O_WRONLY | os.O_RDWR)
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	node, ok := m.getNode(path)
+	if ok {
+		if flag&os.O_CREATE != 0 && flag&os.O_EXCL != 0 {
+			return nil, newPathError("open", name, fs.ErrExist)
+		}
+		if node.mode.IsDir() && accessMode != os.O_RDONLY {
+			return nil, newPathError("open", name, errIsDirectory)
+		}
+		if flag&os.O_TRUNC != 0 && accessMode != os.O_RDONLY {
+			node.data = nil
+			node.modTime = time.Now()
+		}
+	} else {
+		if flag&os.O_CREATE == 0 {
+			return nil, newPathError("open", name, fs.ErrNotExist)
+		}
+		if err := m.checkParentDir(path); err != nil {
+			return nil, newPathError("open", name, err)
+		}
+		node = newMemoryFileNode(nil, perm)
+		m.nodes[path] = node
+	}
+	return &memoryFile{
+		fileSystem: m,
+		name:       name,
+		node:       node,
+		readable:   accessMode != os.O_WRONLY,
+		writable:   accessMode != os.O_RDONLY,
+		append:     flag&os.O_APPEND != 0,
+	}, nil
+}
+
+func (m *memoryFileSystem) ReadFile(name string) ([]byte, error) {
+	path := filepath.Clean(name)
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	node, ok := m.getNode(path)
+	if !ok {
+		return nil, newPathError("open", name, fs.ErrNotExist)
+	}
+	if node.mode.IsDir() {
+		return nil, newPathError("read", name, errIsDirectory)
+	}
+	data := make([]byte, len(node.data))
+	copy(data, node.data)
+	return data, nil
+}
+
+func (m *memoryFileSystem) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	path := filepath.Clean(name)
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	node, ok := m.getNode(path)
+	if ok {
+		if node.mode.IsDir() {
+			return newPathError("open", name, errIsDirectory)
+		}
+		node.data = make([]byte, len(data))
+		copy(node.data, data)
+		node.modTime = time.Now()
+		return nil
+	}
+	if err := m.checkParentDir(path); err != nil {
+		return newPathError("open", name, err)
+	}
+	m.nodes[path] = newMemoryFileNode(data, perm)
+	return nil
+}
+
+func (m *memoryFileSystem) Stat(name string) (fs.FileInfo, error) {
+	path := filepath.Clean(name)
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	node, ok := m.getNode(path)
+	if !ok {
+		return nil, newPathError("stat", name, fs.ErrNotExist)
+	}
+	return node.fileInfo(filepath.Base(path)), nil
+}
+
+func (m *memoryFileSystem) Chtimes(name string, _ time.Time, mtime time.Time) error {
+	path := filepath.Clean(name)
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	node, ok := m.getNode(path)
+	if !ok {
+		return newPathError("chtimes", name, fs.ErrNotExist)
+	}
+	// Access times are not tracked.
+	node.modTime = mtime
+	return nil
+}
+
+// EvalSymlinks returns the cleaned path if it exists, as there are no symbolic links.
+func (m *memoryFileSystem) EvalSymlinks(path string) (string, error) {
+	cleanPath := filepath.Clean(path)
+	if _, err := m.Stat(cleanPath); err != nil {
+		return "", err
+	}
+	return cleanPath, nil
+}
+
+func (m *memoryFileSystem) TryLock(name string, shared bool) (func() error, error) {
+	return m.fileLockTable.tryLock(
+		name,
+		filepath.Clean(name),
+		shared,
+		func() (func() error, error) {
+			file, err := m.OpenFile(name, os.O_CREATE|os.O_RDWR, 0644)
+			if err != nil {
+				return nil, err
+			}
+			if err := file.Close(); err != nil {
+				return nil, err
+			}
+			return func() error { return nil }, nil
+		},
+	)
+}
+
+func (m *memoryFileSystem) ReadDir(name string) ([]fs.DirEntry, error) {
+	path := filepath.Clean(name)
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	node, ok := m.getNode(path)
+	if !ok {
+		return nil, newPathError("open", name, fs.ErrNotExist)
+	}
+	if !node.mode.IsDir() {
+		return nil, newPathError("readdirent", name, errNotDirectory)
+	}
+	var dirEntries []fs.DirEntry
+	for childPath, childNode := range m.nodes {
+		if filepath.Dir(childPath) == path && !isMemoryRoot(childPath) {
+			dirEntries = append(dirEntries, fs.FileInfoToDirEntry(childNode.fileInfo(filepath.Base(childPath))))
+		}
+	}
+	sort.Slice(
+		dirEntries,
+		func(i int, j int) bool {
+			return dirEntries[i].Name() < dirEntries[j].Name()
+		},
+	)
+	return dirEntries, nil
+}
+
+func (m *memoryFileSystem) MkdirAll(path string, perm fs.FileMode) error {
+	cleanPath := filepath.Clean(path)
+	var dirPaths []string
+	for dirPath := cleanPath; !isMemoryRoot(dirPath); dirPath = filepath.Dir(dirPath) {
+		dirPaths = append(dirPaths, dirPath)
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	for i := len(dirPaths) - 1; i >= 0; i-- {
+		dirPath := dirPaths[i]
+		node, ok := m.nodes[dirPath]
+		if !ok {
+			m.nodes[dirPath] = newMemoryDirNode(perm)
+			continue
+		}
+		if !node.mode.IsDir() {
+			return newPathError("mkdir", dirPath, errNotDirectory)
+		}
+	}
+	return nil
+}
+
+func (m *memoryFileSystem) Remove(name string) error {
+	path := filepath.Clean(name)
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	node, ok := m.getNode(path)
+	if !ok {
+		return newPathError("remove", name, fs.ErrNotExist)
+	}
+	if node.mode.IsDir() {
+		if isMemoryRoot(path) {
+			return newPathError("remove", name, errDirectoryNotEmpty)
+		}
+		for childPath := range m.nodes {
+			if isMemoryDescendant(childPath, path) {
+				return newPathError("remove", name, errDirectoryNotEmpty)
+			}
+		}
+	}
+	delete(m.nodes, path)
+	return nil
+}
+
+func (m *memoryFileSystem) RemoveAll(path string) error {
+	cleanPath := filepath.Clean(path)
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	for childPath := range m.nodes {
+		if childPath == cleanPath || isMemoryDescendant(childPath, cleanPath) {
+			delete(m.nodes, childPath)
+		}
+	}
+	return nil
+}
+
+func (m *memoryFileSystem) Rename(oldpath string, newpath string) error {
+	oldPath := filepath.Clean(oldpath)
+	newPath := filepath.Clean(newpath)
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	oldNode, ok := m.getNode(oldPath)
+	if !ok || isMemoryRoot(oldPath) {
+		return newLinkError("rename", oldpath, newpath, fs.ErrNotExist)
+	}
+	if oldPath == newPath {
+		return nil
+	}
+	if isMemoryDescendant(newPath, oldPath) {
+		return newLinkError("rename", oldpath, newpath, fs.ErrInvalid)
+	}
+	if err := m.checkParentDir(newPath); err != nil {
+		return newLinkError("rename", oldpath, newpath, err)
+	}
+	if newNode, ok := m.getNode(newPath); ok {
+		if newNode.mode.IsDir() {
+			if !oldNode.mode.IsDir() {
+				return newLinkError("rename", oldpath, newpath, errIsDirectory)
+			}
+			for childPath := range m.nodes {
+				if isMemoryDescendant(childPath, newPath) {
+					return newLinkError("rename", oldpath, newpath, errDirectoryNotEmpty)
+				}
+			}
+		} else if oldNode.mode.IsDir() {
+			return newLinkError("rename", oldpath, newpath, errNotDirectory)
+		}
+	}
+	movedNodes := make(map[string]*memoryNode)
+	for childPath, childNode := range m.nodes {
+		if childPath == oldPath || isMemoryDescendant(childPath, oldPath) {
+			movedNodes[newPath+strings.TrimPrefix(childPath, oldPath)] = childNode
+			delete(m.nodes, childPath)
+		}
+	}
+	for childPath, childNode := range movedNodes {
+		m.nodes[childPath] = childNode
+	}
+	return nil
+}
+
+func (m *memoryFileSystem) WalkDir(root string, f fs.WalkDirFunc) error {
+	fileInfo, err := m.Stat(root)
+	if err != nil {
+		err = f(root, nil, err)
+	} else {
+		err = m.walkDir(root, fs.FileInfoToDirEntry(fileInfo), f)
+	}
+	if errors.Is(err, fs.SkipDir) || errors.Is(err, fs.SkipAll) {
+		return nil
+	}
+	return err
+}
+
+// walkDir matches the implementation of filepath.WalkDir.
+func (m *memoryFileSystem) walkDir(path string, dirEntry fs.DirEntry, f fs.WalkDirFunc) error {
+	if err := f(path, dirEntry, nil); err != nil || !dirEntry.IsDir() {
+		if errors.Is(err, fs.SkipDir) && dirEntry.IsDir() {
+			err = nil
+		}
+		return err
+	}
+	childDirEntries, err := m.ReadDir(path)
+	if err != nil {
+		if err := f(path, dirEntry, err); err != nil {
+			if errors.Is(err, fs.SkipDir) {
+				err = nil
+			}
+			return err
+		}
+	}
+	for _, childDirEntry := range childDirEntries {
+		if err := m.walkDir(filepath.Join(path, childDirEntry.Name()), childDirEntry, f); err != nil {
+			if errors.Is(err, fs.SkipDir) {
+				break
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// getNode gets the node for the cleaned path.
+//
+// Must be called with the lock held.
+func (m *memoryFileSystem) getNode(path string) (*memoryNode, bool) {
+	if isMemoryRoot(path) {
+		return newMemoryDirNode(0755), true
+	}
+	node, ok := m.nodes[path]
+	return node, ok
+}
+
+// checkParentDir checks that the parent directory of the cleaned path exists.
+//
+// Must be called with the lock held.
+func (m *memoryFileSystem) checkParentDir(path string) error {
+	parentNode, ok := m.getNode(filepath.Dir(path))
+	if !ok {
+		return fs.ErrNotExist
+	}
+	if !parentNode.mode.IsDir() {
+		return errNotDirectory
+	}
+	return nil
+}
+
+type memoryNode struct {
+	data    []byte
+	mode    fs.FileMode
+	modTime time.Time
+}
+
+func newMemoryFileNode(data []byte, perm fs.FileMode) *memoryNode {
+	nodeData := make([]byte, len(data))
+	copy(nodeData, data)
+	return &memoryNode{
+		data:    nodeData,
+		mode:    perm.Perm(),
+		modTime: time.Now(),
+	}
+}
+
+func newMemoryDirNode(perm fs.FileMode) *memoryNode {
+	return &memoryNode{
+		mode:    fs.ModeDir | perm.Perm(),
+		modTime: time.Now(),
+	}
+}
+
+func (n *memoryNode) fileInfo(name string) *memoryFileInfo {
+	return &memoryFileInfo{
+		name:    name,
+		size:    int64(len(n.data)),
+		mode:    n.mode,
+		modTime: n.modTime,
+	}
+}
+
+type memoryFileInfo struct {
+	name    string
+	size    int64
+	mode    fs.FileMode
+	modTime time.Time
+}
+
+func (i *memoryFileInfo) Name() string {
+	return i.name
+}
+
+func (i *memoryFileInfo) Size() int64 {
+	return i.size
+}
+
+func (i *memoryFileInfo) Mode() fs.FileMode {
+	return i.mode
+}
+
+func (i *memoryFileInfo) ModTime() time.Time {
+	return i.modTime
+}
+
+func (i *memoryFileInfo) IsDir() bool {
+	return i.mode.IsDir()
+}
+
+func (*memoryFileInfo) Sys() any {
+	return nil
+}
+
+type memoryFile struct {
+	fileSystem *memoryFileSystem
+	name       string
+	node       *memoryNode
+	readable   bool
+	writable   bool
+	append     bool
+	offset     int
+	closed     bool
+}
+
+func (f *memoryFile) Read(p []byte) (int, error) {
+	f.fileSystem.lock.Lock()
+	defer f.fileSystem.lock.Unlock()
+	if f.closed {
+		return 0, newPathError("read", f.name, fs.ErrClosed)
+	}
+	if !f.readable {
+		return 0, newPathError("read", f.name, errNotOpenForRead)
+	}
+	if f.node.mode.IsDir() {
+		return 0, newPathError("read", f.name, errIsDirectory)
+	}
+	if f.offset >= len(f.node.data) {
+		return 0, io.EOF
+	}
+	n := copy(p, f.node.data[f.offset:])
+	f.offset += n
+	return n, nil
+}
+
+func (f *memoryFile) Write(p []byte) (int, error) {
+	f.fileSystem.lock.Lock()
+	defer f.fileSystem.lock.Unlock()
+	if f.closed {
+		return 0, newPathError("write", f.name, fs.ErrClosed)
+	}
+	if !f.writable {
+		return 0, newPathError("write", f.name, errNotOpenForWrite)
+	}
+	if f.append {
+		f.offset = len(f.node.data)
+	}
+	if end := f.offset + len(p); end > len(f.node.data) {
+		f.node.data = append(f.node.data, make([]byte, end-len(f.node.data))...)
+	}
+	n := copy(f.node.data[f.offset:], p)
+	f.offset += n
+	f.node.modTime = time.Now()
+	return n, nil
+}
+
+func (f *memoryFile) Close() error {
+	f.fileSystem.lock.Lock()
+	defer f.fileSystem.lock.Unlock()
+	if f.closed {
+		return newPathError("close", f.name, fs.ErrClosed)
+	}
+	f.closed = true
+	return nil
+}
+
+func (f *memoryFile) Name() string {
+	return f.name
+}
+
+func (f *memoryFile) Stat() (fs.FileInfo, error) {
+	f.fileSystem.lock.RLock()
+	defer f.fileSystem.lock.RUnlock()
+	if f.closed {
+		return nil, newPathError("stat", f.name, fs.ErrClosed)
+	}
+	return f.node.fileInfo(filepath.Base(f.name)), nil
+}
+
+// isMemoryRoot returns true if the cleaned path is a root, such as "/" or ".".
+func isMemoryRoot(path string) bool {
+	return filepath.Dir(path) == path
+}
+
+// isMemoryDescendant returns true if the cleaned path is a strict descendant of the cleaned dirPath.
+func isMemoryDescendant(path string, dirPath string) bool {
+	if dirPath == "." {
+		return path != "." && !filepath.IsAbs(path) && path != ".." && !strings.HasPrefix(path, ".."+string(filepath.Separator))
+	}
+	prefix := dirPath
+	if !strings.HasSuffix(prefix, string(filepath.Separator)) {
+		prefix += string(filepath.Separator)
+	}
+	return strings.HasPrefix(path, prefix)
+}
+
+func newPathError(op string, path string, err error) error {
+	return &fs.PathError{Op: op, Path: path, Err: err}
+}
+
+func newLinkError(op string, oldpath string, newpath string, err error) error {
+	return &os.LinkError{Op: op, Old: oldpath, New: newpath, Err: err}
+}
@@ -0,0 +1,53 @@
+// Copyright 2025-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"time"
+)
+
+type osClock struct{}
+
+func newOSClock() osClock {
+	return osClock{}
+}
+
+func (osClock) Now() time.Time {
+	return time.Now()
+}
+
+func (osClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+func (osClock) NewTimer(d time.Duration) Timer {
+	return &osTimer{timer: time.NewTimer(d)}
+}
+
+type osTimer struct {
+	timer *time.Timer
+}
+
+func (t *osTimer) C() <-chan time.Time {
+	return t.timer.C
+}
+
+func (t *osTimer) Stop() bool {
+	return t.timer.Stop()
+}
+
+func (t *osTimer) Reset(d time.Duration) bool {
+	return t.timer.Reset(d)
+}
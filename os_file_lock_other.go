@@ -0,0 +1,41 @@
+// Copyright 2025-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !solaris && !windows
+
+package app
+
+import (
+	"os"
+)
+
+func openLockFile(name string) (*os.File, error) {
+	return os.OpenFile(name, os.O_CREATE|os.O_RDWR, 0644)
+}
+
+// lockFile is a no-op, as there are no locks of the operating system on this platform.
+//
+// Locks are only held within the process, by fileLockTable.
+func lockFile(*os.File, bool) error {
+	return nil
+}
+
+func unlockFile(*os.File) error {
+	return nil
+}
+
+// isLockWouldBlock returns true if the error from lockFile means that the lock is held.
+func isLockWouldBlock(error) bool {
+	return false
+}
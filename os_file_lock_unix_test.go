@@ -0,0 +1,53 @@
+// Copyright 2025-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Excluding aix and js,wasm from the unix-like build tags, as flock is not available there.
+
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockOSFile(t *testing.T) {
+	t.Parallel()
+	lockFilePath := filepath.Join(t.TempDir(), "foo.lock")
+	// Each call opens the file separately, as other processes do.
+	unlock, err := lockOSFile(lockFilePath, false)
+	require.NoError(t, err)
+	_, err = lockOSFile(lockFilePath, true)
+	assert.ErrorIs(t, err, ErrLocked)
+	require.NoError(t, os.Remove(lockFilePath))
+	// The lock is on the removed file, so the new file can be locked.
+	otherUnlock, err := lockOSFile(lockFilePath, false)
+	require.NoError(t, err)
+	require.NoError(t, unlock())
+	require.NoError(t, otherUnlock())
+
+	sharedUnlock, err := lockOSFile(lockFilePath, true)
+	require.NoError(t, err)
+	otherSharedUnlock, err := lockOSFile(lockFilePath, true)
+	require.NoError(t, err)
+	_, err = lockOSFile(lockFilePath, false)
+	assert.ErrorIs(t, err, ErrLocked)
+	require.NoError(t, sharedUnlock())
+	require.NoError(t, otherSharedUnlock())
+}
@@ -0,0 +1,134 @@
+// Copyright 2025-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// osFileLockTable is the table of locks held on the operating system within the process.
+var osFileLockTable = newFileLockTable()
+
+type osFileSystem struct{}
+
+func newOSFileSystem() osFileSystem {
+	return osFileSystem{}
+}
+
+func (osFileSystem) OpenFile(name string, flag int, perm fs.FileMode) (File, error) {
+	file, err := os.OpenFile(name, flag, perm)
+	if err != nil {
+		// Do not return a typed nil.
+		return nil, err
+	}
+	return file, nil
+}
+
+func (osFileSystem) ReadFile(name string) ([]byte, error) {
+	return os.ReadFile(name)
+}
+
+func (osFileSystem) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	return os.WriteFile(name, data, perm)
+}
+
+func (osFileSystem) Stat(name string) (fs.FileInfo, error) {
+	return os.Stat(name)
+}
+
+func (osFileSystem) ReadDir(name string) ([]fs.DirEntry, error) {
+	return os.ReadDir(name)
+}
+
+func (osFileSystem) MkdirAll(path string, perm fs.FileMode) error {
+	return os.MkdirAll(path, perm)
+}
+
+func (osFileSystem) Remove(name string) error {
+	return os.Remove(name)
+}
+
+func (osFileSystem) RemoveAll(path string) error {
+	return os.RemoveAll(path)
+}
+
+func (osFileSystem) Rename(oldpath string, newpath string) error {
+	return os.Rename(oldpath, newpath)
+}
+
+func (osFileSystem) WalkDir(root string, f fs.WalkDirFunc) error {
+	return filepath.WalkDir(root, f)
+}
+
+func (osFileSystem) Chtimes(name string, atime time.Time, mtime time.Time) error {
+	return os.Chtimes(name, atime, mtime)
+}
+
+func (osFileSystem) EvalSymlinks(path string) (string, error) {
+	return filepath.EvalSymlinks(path)
+}
+
+func (osFileSystem) TryLock(name string, shared bool) (func() error, error) {
+	path, err := filepath.Abs(name)
+	if err != nil {
+		return nil, err
+	}
+	return osFileLockTable.tryLock(
+		name,
+		path,
+		shared,
+		func() (func() error, error) {
+			return lockOSFile(name, shared)
+		},
+	)
+}
+
+// lockOSFile acquires the lock of the operating system on the file.
+func lockOSFile(name string, shared bool) (func() error, error) {
+	for {
+		file, err := openLockFile(name)
+		if err != nil {
+			return nil, err
+		}
+		if err := lockFile(file, shared); err != nil {
+			if isLockWouldBlock(err) {
+				err = newPathError("lock", name, ErrLocked)
+			}
+			return nil, errors.Join(err, file.Close())
+		}
+		// The previous holder may have removed the file before it released the lock, in
+		// which case the lock is on a file that is no longer at the path, and we retry.
+		fileInfo, err := file.Stat()
+		if err != nil {
+			return nil, errors.Join(err, file.Close())
+		}
+		pathFileInfo, statErr := os.Stat(name)
+		if statErr == nil && os.SameFile(fileInfo, pathFileInfo) {
+			return func() error {
+				return errors.Join(unlockFile(file), file.Close())
+			}, nil
+		}
+		if err := errors.Join(unlockFile(file), file.Close()); err != nil {
+			return nil, err
+		}
+		if statErr != nil && !errors.Is(statErr, fs.ErrNotExist) {
+			return nil, statErr
+		}
+	}
+}
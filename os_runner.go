@@ -0,0 +1,105 @@
+// Copyright 2025-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"buf.build/go/interrupt"
+)
+
+// osRunnerWaitDelay is the time to wait after interrupting a program before killing it.
+const osRunnerWaitDelay = 10 * time.Second
+
+type osRunner struct{}
+
+func newOSRunner() osRunner {
+	return osRunner{}
+}
+
+func (osRunner) Run(ctx context.Context, command *ExecCommand) error {
+	// Cancel on interrupt signals even if the context was not created by Run,
+	// so that the signals are forwarded to the program.
+	ctx, stop := signal.NotifyContext(ctx, interrupt.Signals...)
+	defer stop()
+	filePath, err := lookPath(command.Name, command.Env)
+	if err != nil {
+		return err
+	}
+	cmd := exec.CommandContext(ctx, filePath, command.Args...)
+	cmd.Args[0] = command.Name
+	// A nil Env would result in the program inheriting the environment of the process.
+	cmd.Env = command.Env
+	if cmd.Env == nil {
+		cmd.Env = []string{}
+	}
+	cmd.Dir = command.Dir
+	cmd.Stdin = command.Stdin
+	cmd.Stdout = command.Stdout
+	cmd.Stderr = command.Stderr
+	cmd.Cancel = func() error {
+		// Interrupt is not supported on windows, fall back to killing the program.
+		if err := cmd.Process.Signal(os.Interrupt); err != nil {
+			return cmd.Process.Kill()
+		}
+		return nil
+	}
+	cmd.WaitDelay = osRunnerWaitDelay
+	return cmd.Run()
+}
+
+// lookPath resolves the name of a program without path separators using $PATH from env.
+//
+// exec.LookPath uses the $PATH of the process, which may differ from the environment
+// the program is run with.
+func lookPath(name string, env []string) (string, error) {
+	if name == "" || filepath.Base(name) != name {
+		return name, nil
+	}
+	for _, dirPath := range filepath.SplitList(getEnvironValue(env, "PATH")) {
+		// Relative entries would be resolved against the working directory of the process.
+		if !filepath.IsAbs(dirPath) {
+			continue
+		}
+		if filePath, err := exec.LookPath(filepath.Join(dirPath, name)); err == nil {
+			return filePath, nil
+		}
+	}
+	return "", &exec.Error{Name: name, Err: exec.ErrNotFound}
+}
+
+// getEnvironValue returns the value of the key in env, in the form "KEY=VALUE".
+//
+// If the key is present more than once, the last value is returned, as os/exec does.
+// Keys are case-insensitive on windows.
+func getEnvironValue(env []string, key string) string {
+	for i := len(env) - 1; i >= 0; i-- {
+		envKey, value, ok := strings.Cut(env[i], "=")
+		if !ok {
+			continue
+		}
+		if envKey == key || (runtime.GOOS == "windows" && strings.EqualFold(envKey, key)) {
+			return value
+		}
+	}
+	return ""
+}
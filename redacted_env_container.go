@@ -0,0 +1,102 @@
+// Copyright 2025-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"log/slog"
+	"path"
+	"sort"
+	"strings"
+)
+
+const redactedValue = "[REDACTED]"
+
+var defaultRedactedEnvKeyPatterns = []string{
+	"*TOKEN*",
+	"*SECRET*",
+	"*PASSWORD*",
+	"*_KEY",
+}
+
+type redactedEnvContainer struct {
+	envContainer EnvContainer
+	keyPatterns  []string
+}
+
+func newRedactedEnvContainer(envContainer EnvContainer, keyPatterns []string) *redactedEnvContainer {
+	if len(keyPatterns) == 0 {
+		keyPatterns = defaultRedactedEnvKeyPatterns
+	}
+	upperKeyPatterns := make([]string, len(keyPatterns))
+	for i, keyPattern := range keyPatterns {
+		upperKeyPatterns[i] = strings.ToUpper(keyPattern)
+	}
+	return &redactedEnvContainer{
+		envContainer: envContainer,
+		keyPatterns:  upperKeyPatterns,
+	}
+}
+
+func (r *redactedEnvContainer) Env(key string) string {
+	return r.redact(key, r.envContainer.Env(key))
+}
+
+func (r *redactedEnvContainer) ForEachEnv(f func(string, string)) {
+	r.envContainer.ForEachEnv(
+		func(key string, value string) {
+			f(key, r.redact(key, value))
+		},
+	)
+}
+
+func (r *redactedEnvContainer) LogValue() slog.Value {
+	environMap := EnvironMap(r)
+	keys := make([]string, 0, len(environMap))
+	for key := range environMap {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	attrs := make([]slog.Attr, len(keys))
+	for i, key := range keys {
+		attrs[i] = slog.String(key, environMap[key])
+	}
+	return slog.GroupValue(attrs...)
+}
+
+func (r *redactedEnvContainer) redact(key string, value string) string {
+	if value == "" {
+		return ""
+	}
+	if r.isRedactedKey(key) {
+		return redactedValue
+	}
+	return value
+}
+
+func (r *redactedEnvContainer) isRedactedKey(key string) bool {
+	return isRedactedKey(r.keyPatterns, key)
+}
+
+// isRedactedKey returns true if the key matches any of the upper-case key patterns.
+func isRedactedKey(upperKeyPatterns []string, key string) bool {
+	upperKey := strings.ToUpper(key)
+	for _, keyPattern := range upperKeyPatterns {
+		// path.Match only errors on malformed patterns, in which case we redact to be safe.
+		if matched, err := path.Match(keyPattern, upperKey); matched || err != nil {
+			return true
+		}
+	}
+	return false
+}
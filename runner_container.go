@@ -0,0 +1,43 @@
+// Copyright 2025-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"context"
+	"fmt"
+)
+
+type runnerContainer struct {
+	runner Runner
+}
+
+func newRunnerContainer(runner Runner) *runnerContainer {
+	if runner == nil {
+		runner = errorRunner{}
+	}
+	return &runnerContainer{
+		runner: runner,
+	}
+}
+
+func (r *runnerContainer) Runner() Runner {
+	return r.runner
+}
+
+type errorRunner struct{}
+
+func (errorRunner) Run(_ context.Context, command *ExecCommand) error {
+	return fmt.Errorf("cannot run %s: no Runner was set on the container", command.Name)
+}
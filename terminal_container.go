@@ -0,0 +1,102 @@
+// Copyright 2025-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"os"
+	"sync"
+)
+
+type terminalContainer struct {
+	isStdinTerminal  bool
+	isStdoutTerminal bool
+	isStderrTerminal bool
+	width            int
+	height           int
+}
+
+func newTerminalContainer(
+	isStdinTerminal bool,
+	isStdoutTerminal bool,
+	isStderrTerminal bool,
+	width int,
+	height int,
+) *terminalContainer {
+	return &terminalContainer{
+		isStdinTerminal:  isStdinTerminal,
+		isStdoutTerminal: isStdoutTerminal,
+		isStderrTerminal: isStderrTerminal,
+		width:            max(width, 0),
+		height:           max(height, 0),
+	}
+}
+
+func (t *terminalContainer) IsStdinTerminal() bool {
+	return t.isStdinTerminal
+}
+
+func (t *terminalContainer) IsStdoutTerminal() bool {
+	return t.isStdoutTerminal
+}
+
+func (t *terminalContainer) IsStderrTerminal() bool {
+	return t.isStderrTerminal
+}
+
+func (t *terminalContainer) TerminalSize() (int, int) {
+	return t.width, t.height
+}
+
+type osTerminalContainer struct {
+	isStdinTerminal  bool
+	isStdoutTerminal bool
+	isStderrTerminal bool
+	once             sync.Once
+}
+
+func newOSTerminalContainer() *osTerminalContainer {
+	return &osTerminalContainer{}
+}
+
+func (t *osTerminalContainer) IsStdinTerminal() bool {
+	t.once.Do(t.init)
+	return t.isStdinTerminal
+}
+
+func (t *osTerminalContainer) IsStdoutTerminal() bool {
+	t.once.Do(t.init)
+	return t.isStdoutTerminal
+}
+
+func (t *osTerminalContainer) IsStderrTerminal() bool {
+	t.once.Do(t.init)
+	return t.isStderrTerminal
+}
+
+// TerminalSize is not cached, as the size of the terminal can change while running.
+func (t *osTerminalContainer) TerminalSize() (int, int) {
+	for _, file := range []*os.File{os.Stdout, os.Stderr, os.Stdin} {
+		if width, height, ok := getTerminalSize(file); ok {
+			return width, height
+		}
+	}
+	return 0, 0
+}
+
+func (t *osTerminalContainer) init() {
+	t.isStdinTerminal = isTerminal(os.Stdin)
+	t.isStdoutTerminal = isTerminal(os.Stdout)
+	t.isStderrTerminal = isTerminal(os.Stderr)
+}
@@ -0,0 +1,53 @@
+// Copyright 2025-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux
+
+package app
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+func isTerminal(file *os.File) bool {
+	var termios syscall.Termios
+	_, _, errno := syscall.Syscall(
+		syscall.SYS_IOCTL,
+		file.Fd(),
+		syscall.TCGETS,
+		uintptr(unsafe.Pointer(&termios)),
+	)
+	return errno == 0
+}
+
+func getTerminalSize(file *os.File) (int, int, bool) {
+	var winsize struct {
+		row    uint16
+		col    uint16
+		xpixel uint16
+		ypixel uint16
+	}
+	_, _, errno := syscall.Syscall(
+		syscall.SYS_IOCTL,
+		file.Fd(),
+		syscall.TIOCGWINSZ,
+		uintptr(unsafe.Pointer(&winsize)),
+	)
+	if errno != 0 || winsize.col == 0 {
+		return 0, 0, false
+	}
+	return int(winsize.col), int(winsize.row), true
+}